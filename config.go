@@ -1,29 +1,90 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/gocombo/config/val"
 )
 
-type valuesProviderErrors []error
+// ErrBuildFailed is returned if errors were notified while building the config.
+// Individual errors can be inspected with errors.As or via Errors.
+type ErrBuildFailed struct {
+	Errors []error
+}
 
-func (v valuesProviderErrors) Error() string {
-	result := make([]string, len(v))
-	for i, err := range v {
+func (e ErrBuildFailed) Error() string {
+	result := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
 		result[i] = err.Error()
 	}
 	return "failed building config: " + strings.Join(result, "; ")
 }
 
+func (e ErrBuildFailed) Unwrap() []error {
+	return e.Errors
+}
+
+// ErrSourceLoadFailed is returned if a named source failed to load
+type ErrSourceLoadFailed struct {
+	Source string
+	Err    error
+}
+
+func (e ErrSourceLoadFailed) Error() string {
+	return fmt.Sprintf("failed to load %s: %v", e.Source, e.Err)
+}
+
+func (e ErrSourceLoadFailed) Unwrap() error {
+	return e.Err
+}
+
 type valuesProvider struct {
 	sources []Source
-	errors  valuesProviderErrors
+	errors  []error
+
+	deepMerge          bool
+	arrayMergeStrategy ArrayMergeStrategy
+
+	// keys that were requested, in order of first request
+	keys     []string
+	seenKeys map[string]bool
+
+	// defaults that were used for keys missing in sources
+	defaults map[string]val.Raw
+
+	// secretKeys are keys defined as secrets, their values are redacted
+	secretKeys map[string]bool
+
+	interpolation bool
+	resolvers     map[string]Resolver
+
+	// failedKeys are keys that failed to interpolate or resolve, so they
+	// are not reported as missing for the second time
+	failedKeys map[string]bool
 }
 
-// Get returns the value for the given key or false
-func (p *valuesProvider) Get(key string) (val.Raw, bool) {
+func newValuesProvider(sources []Source, opts *loadOpts) *valuesProvider {
+	return &valuesProvider{
+		sources:            sources,
+		deepMerge:          opts.deepMerge,
+		arrayMergeStrategy: opts.arrayMergeStrategy,
+		seenKeys:           map[string]bool{},
+		defaults:           map[string]val.Raw{},
+		secretKeys:         map[string]bool{},
+		interpolation:      opts.interpolation,
+		resolvers:          opts.resolvers,
+		failedKeys:         map[string]bool{},
+	}
+}
+
+func (p *valuesProvider) lookup(key string) (val.Raw, bool) {
+	if p.deepMerge {
+		return p.lookupMerged(key)
+	}
 	for i := range p.sources {
 		src := p.sources[len(p.sources)-1-i]
 		if v, ok := src.GetValue(key); ok {
@@ -33,18 +94,108 @@ func (p *valuesProvider) Get(key string) (val.Raw, bool) {
 	return val.Raw{}, false
 }
 
+// Get returns the value for the given key or false
+func (p *valuesProvider) Get(key string) (val.Raw, bool) {
+	if !p.seenKeys[key] {
+		p.seenKeys[key] = true
+		p.keys = append(p.keys, key)
+	}
+	raw, ok := p.lookup(key)
+	if !ok {
+		return val.Raw{}, false
+	}
+	if p.interpolation {
+		interpolated, err := mapStrings(key, raw.Val, func(strKey, s string) (interface{}, error) {
+			interpolated, err := p.interpolate(s, []string{strKey})
+			if err != nil {
+				return nil, ErrInterpolationFailed{Key: strKey, Err: err}
+			}
+			return interpolated, nil
+		})
+		if err != nil {
+			p.failedKeys[key] = true
+			p.NotifyError(key, err)
+			return val.Raw{}, false
+		}
+		raw.Val = interpolated
+	}
+	return p.resolve(key, raw)
+}
+
 // NotifyError notifies the provider of an error
 // that may occur when parsing or is value is missing
 func (p *valuesProvider) NotifyError(key string, err error) {
+	var missingErr val.ErrMissingValue
+	if errors.As(err, &missingErr) && p.failedKeys[key] {
+		// Interpolation or resolve error was already notified
+		return
+	}
+	if errors.As(err, &missingErr) && len(missingErr.Sources) == 0 {
+		missingErr.Sources = p.sourceNames()
+		err = missingErr
+	}
 	p.errors = append(p.errors, err)
 }
 
+// NotifyDefault notifies the provider that a default value was used
+func (p *valuesProvider) NotifyDefault(raw val.Raw) {
+	p.defaults[raw.Key] = raw
+}
+
+// NotifySecret notifies the provider that the key holds a secret
+func (p *valuesProvider) NotifySecret(key string) {
+	p.secretKeys[key] = true
+}
+
+func (p *valuesProvider) sourceNames() []string {
+	names := make([]string, len(p.sources))
+	for i, src := range p.sources {
+		names[i] = sourceName(src, i)
+	}
+	return names
+}
+
 type SourceLoader func() (Source, error)
 
 type Source interface {
 	GetValue(key string) (val.Raw, bool)
 }
 
+// Named can optionally be implemented by a Source to provide
+// a human readable name that is used in errors and value origin
+type Named interface {
+	Name() string
+}
+
+// sourceName returns name of the source if it implements Named,
+// otherwise a name based on the index of the source is returned
+func sourceName(src Source, index int) string {
+	if named, ok := src.(Named); ok {
+		return named.Name()
+	}
+	return fmt.Sprintf("source #%d", index+1)
+}
+
+// NamedSourceLoader wraps the loader so that errors
+// it returns are attributed to the source with given name
+func NamedSourceLoader(name string, loader SourceLoader) SourceLoader {
+	return func() (Source, error) {
+		src, err := loader()
+		if err != nil {
+			return nil, ErrSourceLoadFailed{Source: name, Err: err}
+		}
+		return src, nil
+	}
+}
+
+// Watchable can optionally be implemented by a Source
+// to signal that its data has changed and config should be reloaded.
+// Watch should block until ctx is done and call notify on every change.
+// ctx is also cancelled once the source is replaced by a reloaded one.
+type Watchable interface {
+	Watch(ctx context.Context, notify func())
+}
+
 type LoadOpts interface {
 	AddSourceLoader(loader SourceLoader)
 }
@@ -53,19 +204,70 @@ type LoadOpt func(opts LoadOpts)
 
 type configFactory[T any] func(p val.Provider) *T
 
+// Validator can be implemented by a config type to validate
+// the whole config once it is built, e.g to check related fields.
+// Errors are reported along with errors of individual values.
+type Validator interface {
+	Validate() error
+}
+
+type configValidator func(cfg interface{}) error
+
+// WithValidator adds a func that validates the whole config once it is built.
+// Errors are reported along with errors of individual values.
+func WithValidator[T any](validate func(cfg *T) error) LoadOpt {
+	return withLoadOpts(func(opts *loadOpts) {
+		opts.validators = append(opts.validators, func(cfg interface{}) error {
+			typedCfg, ok := cfg.(*T)
+			if !ok {
+				return fmt.Errorf("validator for %T can not be used with %T", typedCfg, cfg)
+			}
+			return validate(typedCfg)
+		})
+	})
+}
+
+// Bind returns a config factory that builds T using
+// `config` struct tags instead of a hand written factory.
+// See val.Bind for details.
+func Bind[T any]() func(p val.Provider) *T {
+	return val.Bind[T]
+}
+
 type loadOpts struct {
-	sourceLoaders []SourceLoader
+	sourceLoaders      []SourceLoader
+	reloadInterval     time.Duration
+	onReloadError      func(err error)
+	deepMerge          bool
+	arrayMergeStrategy ArrayMergeStrategy
+	validators         []configValidator
+	interpolation      bool
+	resolvers          map[string]Resolver
 }
 
 func (opts *loadOpts) AddSourceLoader(loader SourceLoader) {
 	opts.sourceLoaders = append(opts.sourceLoaders, loader)
 }
 
-func Load[T any](factory configFactory[T], optsSetters ...LoadOpt) (*T, error) {
-	opts := loadOpts{}
+// withLoadOpts is used to define options that are specific
+// to the config package and not exposed to sources via LoadOpts
+func withLoadOpts(setOpts func(opts *loadOpts)) LoadOpt {
+	return func(opts LoadOpts) {
+		if o, ok := opts.(*loadOpts); ok {
+			setOpts(o)
+		}
+	}
+}
+
+func newLoadOpts(optsSetters []LoadOpt) *loadOpts {
+	opts := &loadOpts{}
 	for _, optSetter := range optsSetters {
-		optSetter(&opts)
+		optSetter(opts)
 	}
+	return opts
+}
+
+func (opts *loadOpts) loadSources() ([]Source, error) {
 	if len(opts.sourceLoaders) == 0 {
 		return nil, fmt.Errorf("no sources provided")
 	}
@@ -77,14 +279,34 @@ func Load[T any](factory configFactory[T], optsSetters ...LoadOpt) (*T, error) {
 		}
 		sources[i] = source
 	}
+	return sources, nil
+}
 
-	provider := &valuesProvider{
-		sources: sources,
-	}
-
+func build[T any](factory configFactory[T], provider *valuesProvider, validators []configValidator) (*T, error) {
 	cfg := factory(provider)
+	if cfg != nil {
+		if v, ok := interface{}(cfg).(Validator); ok {
+			if err := v.Validate(); err != nil {
+				provider.errors = append(provider.errors, err)
+			}
+		}
+		for _, validate := range validators {
+			if err := validate(cfg); err != nil {
+				provider.errors = append(provider.errors, err)
+			}
+		}
+	}
 	if provider.errors != nil {
-		return nil, provider.errors
+		return nil, ErrBuildFailed{Errors: provider.errors}
 	}
 	return cfg, nil
 }
+
+func Load[T any](factory configFactory[T], optsSetters ...LoadOpt) (*T, error) {
+	opts := newLoadOpts(optsSetters)
+	sources, err := opts.loadSources()
+	if err != nil {
+		return nil, err
+	}
+	return build(factory, newValuesProvider(sources, opts), opts.validators)
+}
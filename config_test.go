@@ -19,6 +19,27 @@ func (m *mockKeyValueSource) GetValue(key string) (val.Raw, bool) {
 	return v, ok
 }
 
+type mockNamedSource struct {
+	mockKeyValueSource
+	name string
+}
+
+func (m *mockNamedSource) Name() string {
+	return m.name
+}
+
+type validatedConfig struct {
+	readTimeout  int
+	writeTimeout int
+}
+
+func (c *validatedConfig) Validate() error {
+	if c.readTimeout >= c.writeTimeout {
+		return errors.New("readTimeout must be less than writeTimeout")
+	}
+	return nil
+}
+
 func TestLoad(t *testing.T) {
 	type config struct {
 		val1 string
@@ -120,7 +141,207 @@ func TestLoad(t *testing.T) {
 		if !assert.Error(t, gotErr) {
 			return
 		}
-		assert.EqualError(t, gotErr, "failed building config: value val1 not found; value val2 not found; value val3 not found")
+		assert.EqualError(t, gotErr, "failed building config: "+
+			"value val1 not found (sources: source #1); "+
+			"value val2 not found (sources: source #1); "+
+			"value val3 not found (sources: source #1)",
+		)
+	})
+	t.Run("attribute errors to named sources", func(t *testing.T) {
+		name1 := gofakeit.Generate("{word}.json")
+		name2 := gofakeit.Generate("{word}.json")
+		withNamedSource := func(src *mockNamedSource) LoadOpt {
+			return func(opts LoadOpts) {
+				opts.AddSourceLoader(func() (Source, error) {
+					return src, nil
+				})
+			}
+		}
+		_, gotErr := Load(
+			testConfigFactory,
+			withNamedSource(&mockNamedSource{
+				name: name1,
+				mockKeyValueSource: mockKeyValueSource{
+					values: map[string]val.Raw{
+						"val1": {Key: "val1", Val: gofakeit.Date(), Origin: val.Origin{Source: name1}},
+						"val2": {Key: "val2", Val: gofakeit.Word(), Origin: val.Origin{Source: name1}},
+					},
+				},
+			}),
+			withNamedSource(&mockNamedSource{
+				name:               name2,
+				mockKeyValueSource: mockKeyValueSource{values: map[string]val.Raw{}},
+			}),
+		)
+		if !assert.Error(t, gotErr) {
+			return
+		}
+		assert.Contains(t, gotErr.Error(), fmt.Sprintf("error converting path val1 from %s", name1))
+		assert.Contains(t, gotErr.Error(), fmt.Sprintf("value val3 not found (sources: %s, %s)", name1, name2))
+	})
+	t.Run("attribute load errors to named source loader", func(t *testing.T) {
+		name := gofakeit.Generate("{word}.json")
+		wantErr := errors.New(gofakeit.Sentence(3))
+		_, gotErr := Load(
+			testConfigFactory,
+			func(opts LoadOpts) {
+				opts.AddSourceLoader(NamedSourceLoader(name, func() (Source, error) {
+					return nil, wantErr
+				}))
+			},
+		)
+		assert.ErrorIs(t, gotErr, wantErr)
+		assert.EqualError(t, gotErr, fmt.Sprintf("failed to load %s: %s", name, wantErr))
+		var loadErr ErrSourceLoadFailed
+		if assert.ErrorAs(t, gotErr, &loadErr) {
+			assert.Equal(t, name, loadErr.Source)
+		}
+	})
+	t.Run("inspect notified errors", func(t *testing.T) {
+		_, gotErr := Load(
+			testConfigFactory,
+			withMockSource(&mockKeyValueSource{
+				values: map[string]val.Raw{
+					"val1": {Key: "val1", Val: gofakeit.Date()},
+					"val2": {Key: "val2", Val: gofakeit.Word()},
+				},
+			}, nil),
+		)
+		var buildErr ErrBuildFailed
+		if !assert.ErrorAs(t, gotErr, &buildErr) {
+			return
+		}
+		if !assert.Len(t, buildErr.Errors, 2) {
+			return
+		}
+		var conversionErr val.ErrConversion
+		if assert.ErrorAs(t, gotErr, &conversionErr) {
+			assert.Equal(t, "val1", conversionErr.Key)
+			assert.Equal(t, "string", conversionErr.TargetType)
+		}
+		assert.Equal(t, val.ErrMissingValue{Key: "val3", Sources: []string{"source #1"}}, buildErr.Errors[1])
+	})
+	t.Run("report all validation errors", func(t *testing.T) {
+		_, gotErr := Load(
+			func(p val.Provider) *config {
+				return &config{
+					val1: val.Define[string](p, "val1", val.NotEmpty(), val.OneOf("a", "b")),
+					val2: val.Define[string](p, "val2", val.OneOf("a", "b")),
+				}
+			},
+			withMockSource(&mockKeyValueSource{
+				values: map[string]val.Raw{
+					"val1": {Key: "val1", Val: ""},
+					"val2": {Key: "val2", Val: "c"},
+				},
+			}, nil),
+		)
+		var buildErr ErrBuildFailed
+		if !assert.ErrorAs(t, gotErr, &buildErr) {
+			return
+		}
+		assert.Len(t, buildErr.Errors, 3)
+		for _, err := range buildErr.Errors {
+			assert.ErrorAs(t, err, &val.ErrInvalidValue{})
+		}
+	})
+	t.Run("validate built config", func(t *testing.T) {
+		validatedConfigFactory := func(p val.Provider) *validatedConfig {
+			return &validatedConfig{
+				readTimeout:  val.Define[int](p, "readTimeout"),
+				writeTimeout: val.Define[int](p, "writeTimeout"),
+			}
+		}
+		withTimeouts := func(readTimeout, writeTimeout int) LoadOpt {
+			return withMockSource(&mockKeyValueSource{
+				values: map[string]val.Raw{
+					"readTimeout":  {Key: "readTimeout", Val: readTimeout},
+					"writeTimeout": {Key: "writeTimeout", Val: writeTimeout},
+				},
+			}, nil)
+		}
+		t.Run("valid", func(t *testing.T) {
+			readTimeout := gofakeit.Number(1, 10)
+			writeTimeout := gofakeit.Number(11, 20)
+			got, err := Load(validatedConfigFactory, withTimeouts(readTimeout, writeTimeout))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, &validatedConfig{readTimeout: readTimeout, writeTimeout: writeTimeout}, got)
+		})
+		t.Run("invalid", func(t *testing.T) {
+			_, err := Load(validatedConfigFactory, withTimeouts(gofakeit.Number(11, 20), gofakeit.Number(1, 10)))
+			assert.EqualError(t, err, "failed building config: readTimeout must be less than writeTimeout")
+		})
+		t.Run("with validators", func(t *testing.T) {
+			wantErr1 := errors.New(gofakeit.Sentence(3))
+			wantErr2 := errors.New(gofakeit.Sentence(3))
+			_, err := Load(
+				validatedConfigFactory,
+				withTimeouts(gofakeit.Number(11, 20), gofakeit.Number(1, 10)),
+				WithValidator(func(cfg *validatedConfig) error {
+					return wantErr1
+				}),
+				WithValidator(func(cfg *validatedConfig) error {
+					return nil
+				}),
+				WithValidator(func(cfg *validatedConfig) error {
+					return wantErr2
+				}),
+			)
+			var buildErr ErrBuildFailed
+			if !assert.ErrorAs(t, err, &buildErr) {
+				return
+			}
+			assert.Len(t, buildErr.Errors, 3)
+			assert.ErrorIs(t, err, wantErr1)
+			assert.ErrorIs(t, err, wantErr2)
+		})
+		t.Run("merge with value errors", func(t *testing.T) {
+			wantErr := errors.New(gofakeit.Sentence(3))
+			_, err := Load(
+				testConfigFactory,
+				withMockSource(&mockKeyValueSource{values: map[string]val.Raw{}}, nil),
+				WithValidator(func(cfg *config) error {
+					return wantErr
+				}),
+			)
+			var buildErr ErrBuildFailed
+			if !assert.ErrorAs(t, err, &buildErr) {
+				return
+			}
+			assert.Len(t, buildErr.Errors, 4)
+			assert.ErrorIs(t, err, wantErr)
+		})
+		t.Run("validator of other type", func(t *testing.T) {
+			_, err := Load(
+				validatedConfigFactory,
+				withTimeouts(gofakeit.Number(1, 10), gofakeit.Number(11, 20)),
+				WithValidator(func(cfg *config) error {
+					return nil
+				}),
+			)
+			assert.Error(t, err)
+		})
+	})
+	t.Run("load with struct tags", func(t *testing.T) {
+		type taggedConfig struct {
+			Val1 string `config:"val1"`
+			Val2 string `config:"val2,optional"`
+		}
+		want := &taggedConfig{Val1: gofakeit.Word()}
+		got, err := Load(
+			Bind[taggedConfig](),
+			withMockSource(&mockKeyValueSource{
+				values: map[string]val.Raw{
+					"val1": {Key: "val1", Val: want.Val1},
+				},
+			}, nil),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, want, got)
 	})
 	t.Run("fail if no sources", func(t *testing.T) {
 		_, err := Load(
@@ -0,0 +1,374 @@
+package consulsrc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocombo/config"
+	"github.com/gocombo/config/internal/keypath"
+	"github.com/gocombo/config/val"
+)
+
+// DefaultAddress is an address of the local Consul agent
+const DefaultAddress = "http://127.0.0.1:8500"
+
+// DefaultPollInterval is used to check the prefix for changes
+// if blocking queries are not enabled. Also used to back off
+// if request has failed when config is watched.
+const DefaultPollInterval = 30 * time.Second
+
+// DefaultTimeout is a timeout of a single request,
+// blocking queries are allowed to take the wait time longer
+const DefaultTimeout = 10 * time.Second
+
+type loadOpts struct {
+	name          string
+	address       string
+	token         string
+	datacenter    string
+	client        *http.Client
+	timeout       time.Duration
+	pollInterval  time.Duration
+	blockingQuery bool
+	blockingWait  time.Duration
+}
+
+func defaultLoadOpts() loadOpts {
+	return loadOpts{
+		name:         "consul",
+		address:      DefaultAddress,
+		client:       http.DefaultClient,
+		timeout:      DefaultTimeout,
+		pollInterval: DefaultPollInterval,
+	}
+}
+
+func (o *loadOpts) set(optSetter []LoadOpt) {
+	for _, opt := range optSetter {
+		opt(o)
+	}
+}
+
+type LoadOpt func(opts *loadOpts)
+
+// WithName sets a name of the source that is used in errors
+// and value origin. Default name is "consul".
+func WithName(name string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.name = name
+	}
+}
+
+// WithAddress sets an address of Consul HTTP API. Default is DefaultAddress.
+func WithAddress(address string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.address = address
+	}
+}
+
+// WithToken sets ACL token that is sent with every request
+func WithToken(token string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.token = token
+	}
+}
+
+// WithDatacenter sets a datacenter to read keys from.
+// Datacenter of the agent is used by default.
+func WithDatacenter(datacenter string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.datacenter = datacenter
+	}
+}
+
+// WithClient sets a client that is used to send requests.
+// http.DefaultClient is used by default.
+func WithClient(client *http.Client) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.client = client
+	}
+}
+
+// WithTimeout sets a timeout of a single request. Default is DefaultTimeout,
+// non-positive values are ignored.
+func WithTimeout(timeout time.Duration) LoadOpt {
+	return func(opts *loadOpts) {
+		if timeout > 0 {
+			opts.timeout = timeout
+		}
+	}
+}
+
+// WithPollInterval defines how often the prefix is checked for changes
+// when config is watched. Default is DefaultPollInterval, non-positive
+// values are ignored.
+func WithPollInterval(interval time.Duration) LoadOpt {
+	return func(opts *loadOpts) {
+		if interval > 0 {
+			opts.pollInterval = interval
+		}
+	}
+}
+
+// WithBlockingQueries makes Watch use Consul blocking queries instead of
+// polling, so changes are noticed as soon as they happen. Wait is a maximum
+// duration of a single query, Consul default (5m) is used if it's zero.
+func WithBlockingQueries(wait time.Duration) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.blockingQuery = true
+		opts.blockingWait = wait
+	}
+}
+
+type kvPair struct {
+	Key   string
+	Value *string
+}
+
+type kvState struct {
+	index uint64
+
+	// hash is compared since index may change without
+	// any changes of the keys under the prefix
+	hash [sha256.Size]byte
+}
+
+type source struct {
+	name      string
+	prefix    string
+	opts      loadOpts
+	state     kvState
+	rawValues map[string]interface{}
+}
+
+func (src *source) Name() string {
+	return src.name
+}
+
+// query returns query parameters and a timeout of the request
+// reading keys, blocking query is sent if the index is set
+func (src *source) query(index uint64) (url.Values, time.Duration) {
+	query := url.Values{"recurse": {"true"}}
+	if src.opts.datacenter != "" {
+		query.Set("dc", src.opts.datacenter)
+	}
+	timeout := src.opts.timeout
+	if index > 0 {
+		query.Set("index", strconv.FormatUint(index, 10))
+		if src.opts.blockingWait > 0 {
+			query.Set("wait", src.opts.blockingWait.String())
+			timeout += src.opts.blockingWait
+		} else {
+			timeout += 5 * time.Minute
+		}
+	}
+	return query, timeout
+}
+
+func readPairs(res *http.Response) ([]kvPair, kvState, error) {
+	state := kvState{}
+	if indexHeader := res.Header.Get("X-Consul-Index"); indexHeader != "" {
+		var err error
+		state.index, err = strconv.ParseUint(indexHeader, 10, 64)
+		if err != nil {
+			return nil, kvState{}, fmt.Errorf("invalid X-Consul-Index: %w", err)
+		}
+	}
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		// No keys under the prefix
+		return nil, state, nil
+	default:
+		return nil, kvState{}, fmt.Errorf("unexpected status: %s", res.Status)
+	}
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, kvState{}, fmt.Errorf("failed to read response: %w", err)
+	}
+	state.hash = sha256.Sum256(data)
+	var pairs []kvPair
+	if err := json.Unmarshal(data, &pairs); err != nil {
+		return nil, kvState{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return pairs, state, nil
+}
+
+func (src *source) fetch(ctx context.Context, index uint64) ([]kvPair, kvState, error) {
+	query, timeout := src.query(index)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	reqURL := strings.TrimSuffix(src.opts.address, "/") + "/v1/kv/" + src.prefix + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, kvState{}, err
+	}
+	if src.opts.token != "" {
+		req.Header.Set("X-Consul-Token", src.opts.token)
+	}
+	res, err := src.opts.client.Do(req)
+	if err != nil {
+		return nil, kvState{}, err
+	}
+	defer res.Body.Close()
+	return readPairs(res)
+}
+
+func (src *source) setValues(pairs []kvPair) error {
+	for _, pair := range pairs {
+		key := strings.TrimPrefix(pair.Key, src.prefix)
+
+		// Folders created in UI have no value
+		if pair.Value == nil || key == "" || strings.HasSuffix(key, "/") {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(*pair.Value)
+		if err != nil {
+			return fmt.Errorf("failed to decode value of %s: %w", pair.Key, err)
+		}
+		if err := setValue(src.rawValues, key, string(data)); err != nil {
+			return fmt.Errorf("failed to set value of %s: %w", pair.Key, err)
+		}
+	}
+	return nil
+}
+
+// setValue puts the value to values under "/" separated key. Keys can't
+// have both a value and nested keys, so conflicts are reported as errors.
+func setValue(values map[string]interface{}, key, value string) error {
+	segments := strings.Split(key, "/")
+	parent := values
+	for i, segment := range segments[:len(segments)-1] {
+		existing, ok := parent[segment]
+		if !ok {
+			existing = map[string]interface{}{}
+			parent[segment] = existing
+		}
+		nested, ok := existing.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s has a value and can't have nested keys", strings.Join(segments[:i+1], "/"))
+		}
+		parent = nested
+	}
+	leaf := segments[len(segments)-1]
+	if _, ok := parent[leaf].(map[string]interface{}); ok {
+		return fmt.Errorf("%s has nested keys and can't have a value", key)
+	}
+	parent[leaf] = value
+	return nil
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// Watch checks keys under the prefix for changes. Blocking queries
+// are used if enabled with WithBlockingQueries, otherwise keys are polled.
+// Failed requests are retried after the poll interval.
+func (src *source) Watch(ctx context.Context, notify func()) {
+	interval := src.opts.pollInterval
+	current := src.state
+	for {
+		// Blocking query returns immediately without index, so
+		// polling is used until Consul responds with an index
+		blocking := src.opts.blockingQuery && current.index > 0
+		if !blocking && !sleep(ctx, interval) {
+			return
+		}
+		state, changed, err := src.check(ctx, current, blocking)
+		if err != nil {
+			if blocking && !sleep(ctx, interval) {
+				return
+			}
+			continue
+		}
+		current = state
+		if changed {
+			notify()
+		}
+	}
+}
+
+// check reads the state of keys under the prefix using blocking query
+// if blocking is true and reports if keys have changed since current state
+func (src *source) check(ctx context.Context, current kvState, blocking bool) (kvState, bool, error) {
+	var index uint64
+	if blocking {
+		index = current.index
+	}
+	_, state, err := src.fetch(ctx, index)
+	if err != nil {
+		return current, false, err
+	}
+	if state.index < current.index {
+		// Index went backwards, Consul suggests to start over
+		state.index = 0
+	}
+	return state, state.hash != current.hash, nil
+}
+
+func (src *source) GetValue(key string) (val.Raw, bool) {
+	if v := keypath.Lookup(key, src.rawValues); v != nil {
+		return val.Raw{
+			Key: key,
+			Val: v,
+			Origin: val.Origin{
+				Source: src.name,
+			},
+		}, true
+	}
+	return val.Raw{}, false
+}
+
+// Load reads all keys under the prefix from Consul KV store. The prefix is
+// removed from keys, so prefix/server/port is available as server/port.
+// Nested values can be read as maps or structs, e.g server.
+func Load(prefix string, optSetter ...LoadOpt) config.LoadOpt {
+	return func(opts config.LoadOpts) {
+		srcOpts := defaultLoadOpts()
+		srcOpts.set(optSetter)
+		opts.AddSourceLoader(config.NamedSourceLoader(srcOpts.name, func() (config.Source, error) {
+			return load(prefix, optSetter...)
+		}))
+	}
+}
+
+func load(prefix string, optSetter ...LoadOpt) (config.Source, error) {
+	opts := defaultLoadOpts()
+	opts.set(optSetter)
+	prefix = strings.Trim(prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	src := &source{
+		name:      opts.name,
+		prefix:    prefix,
+		opts:      opts,
+		rawValues: map[string]interface{}{},
+	}
+	pairs, state, err := src.fetch(context.Background(), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", prefix, err)
+	}
+	if err := src.setValues(pairs); err != nil {
+		return nil, err
+	}
+	src.state = state
+	return src, nil
+}
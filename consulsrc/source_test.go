@@ -0,0 +1,362 @@
+package consulsrc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/gocombo/config"
+	"github.com/gocombo/config/val"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockLoadOpts struct {
+	sourceLoaders []config.SourceLoader
+}
+
+func (m *mockLoadOpts) AddSourceLoader(loader config.SourceLoader) {
+	m.sourceLoaders = append(m.sourceLoaders, loader)
+}
+
+// mockConsul is a minimal fake of Consul KV HTTP API
+type mockConsul struct {
+	mu       sync.Mutex
+	values   map[string]*string
+	index    uint64
+	status   int
+	requests []*http.Request
+}
+
+func (m *mockConsul) set(key string, value *string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.values == nil {
+		m.values = map[string]*string{}
+	}
+	m.values[key] = value
+	m.index++
+}
+
+func (m *mockConsul) requestsCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.requests)
+}
+
+func (m *mockConsul) lastRequest() *http.Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.requests[len(m.requests)-1]
+}
+
+func (m *mockConsul) currentIndex() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.index
+}
+
+func (m *mockConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	m.requests = append(m.requests, r)
+	status := m.status
+	m.mu.Unlock()
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+	if index, err := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64); err == nil {
+		wait, _ := time.ParseDuration(r.URL.Query().Get("wait"))
+		deadline := time.Now().Add(wait)
+		for m.currentIndex() == index && time.Now().Before(deadline) && r.Context().Err() == nil {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prefix := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	type kvPair struct {
+		Key   string
+		Value *string
+	}
+	var pairs []kvPair
+	for key, value := range m.values {
+		if strings.HasPrefix(key, prefix) {
+			pairs = append(pairs, kvPair{Key: key, Value: value})
+		}
+	}
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(m.index, 10))
+	if len(pairs) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(pairs)
+}
+
+func encoded(value string) *string {
+	encodedVal := base64.StdEncoding.EncodeToString([]byte(value))
+	return &encodedVal
+}
+
+func TestConsulSource(t *testing.T) {
+	startConsul := func(t *testing.T) (*mockConsul, *httptest.Server) {
+		mock := &mockConsul{}
+		srv := httptest.NewServer(mock)
+		t.Cleanup(srv.Close)
+		return mock, srv
+	}
+
+	t.Run("load", func(t *testing.T) {
+		loadFromOpts := func(prefix string, opts ...LoadOpt) (config.Source, error) {
+			mockOpts := &mockLoadOpts{}
+			loadOpt := Load(prefix, opts...)
+			loadOpt(mockOpts)
+			if len(mockOpts.sourceLoaders) < 1 {
+				return nil, fmt.Errorf("no source loader added to opts")
+			}
+			return mockOpts.sourceLoaders[0]()
+		}
+		t.Run("read values under prefix", func(t *testing.T) {
+			mock, srv := startConsul(t)
+			prefix := gofakeit.Word()
+			val1 := gofakeit.SentenceSimple()
+			val2 := gofakeit.SentenceSimple()
+			mock.set(prefix+"/", nil)
+			mock.set(prefix+"/str_val_1", encoded(val1))
+			mock.set(prefix+"/server/", nil)
+			mock.set(prefix+"/server/host", encoded(val2))
+			mock.set(prefix+"-other/str_val_1", encoded(gofakeit.SentenceSimple()))
+			source, err := loadFromOpts(prefix, WithAddress(srv.URL))
+			if !assert.NoError(t, err) {
+				return
+			}
+			got, ok := source.GetValue("str_val_1")
+			if assert.True(t, ok, "Value str_val_1 not found") {
+				assert.Equal(t, val.Raw{
+					Key:    "str_val_1",
+					Val:    val1,
+					Origin: val.Origin{Source: "consul"},
+				}, got)
+			}
+			got, ok = source.GetValue("server/host")
+			if assert.True(t, ok, "Value server/host not found") {
+				assert.Equal(t, val2, got.Val)
+			}
+			got, ok = source.GetValue("server")
+			if assert.True(t, ok, "Value server not found") {
+				assert.Equal(t, map[string]interface{}{"host": val2}, got.Val)
+			}
+			assert.Equal(t, "true", mock.lastRequest().URL.Query().Get("recurse"))
+		})
+		t.Run("handle missing prefix", func(t *testing.T) {
+			_, srv := startConsul(t)
+			source, err := loadFromOpts(gofakeit.Word(), WithAddress(srv.URL))
+			if !assert.NoError(t, err) {
+				return
+			}
+			_, ok := source.GetValue("str_val_1")
+			assert.False(t, ok, "Value str_val_1 found")
+		})
+		t.Run("send token and datacenter", func(t *testing.T) {
+			mock, srv := startConsul(t)
+			token := gofakeit.UUID()
+			dc := gofakeit.Word()
+			_, err := loadFromOpts(gofakeit.Word(), WithAddress(srv.URL), WithToken(token), WithDatacenter(dc))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, token, mock.lastRequest().Header.Get("X-Consul-Token"))
+			assert.Equal(t, dc, mock.lastRequest().URL.Query().Get("dc"))
+		})
+		t.Run("set source name", func(t *testing.T) {
+			_, srv := startConsul(t)
+			name := gofakeit.Word()
+			source, err := loadFromOpts(gofakeit.Word(), WithAddress(srv.URL), WithName(name))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, name, source.(config.Named).Name())
+		})
+		t.Run("fail on timeout", func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+			}))
+			defer srv.Close()
+			_, err := loadFromOpts(gofakeit.Word(), WithAddress(srv.URL), WithTimeout(10*time.Millisecond))
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		})
+		t.Run("use default timeout if not positive", func(t *testing.T) {
+			mock, srv := startConsul(t)
+			prefix := gofakeit.Word()
+			mock.set(prefix+"/key", encoded(gofakeit.SentenceSimple()))
+			_, err := loadFromOpts(prefix, WithAddress(srv.URL), WithTimeout(0))
+			assert.NoError(t, err)
+			_, err = loadFromOpts(prefix, WithAddress(srv.URL), WithTimeout(-time.Second))
+			assert.NoError(t, err)
+		})
+		t.Run("use given client", func(t *testing.T) {
+			_, srv := startConsul(t)
+			var used bool
+			client := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				used = true
+				return http.DefaultTransport.RoundTrip(r)
+			})}
+			_, err := loadFromOpts(gofakeit.Word(), WithAddress(srv.URL), WithClient(client))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.True(t, used, "client not used")
+		})
+		t.Run("fail on unexpected status", func(t *testing.T) {
+			mock, srv := startConsul(t)
+			mock.status = http.StatusForbidden
+			prefix := gofakeit.Word()
+			name := gofakeit.Word()
+			_, err := loadFromOpts(prefix, WithAddress(srv.URL), WithName(name))
+			assert.EqualError(t, err, fmt.Sprintf(
+				"failed to load %s: failed to read %s/: unexpected status: 403 Forbidden", name, prefix,
+			))
+		})
+		t.Run("fail on invalid value", func(t *testing.T) {
+			mock, srv := startConsul(t)
+			prefix := gofakeit.Word()
+			invalid := "not base64!"
+			mock.set(prefix+"/key", &invalid)
+			_, err := loadFromOpts(prefix, WithAddress(srv.URL))
+			assert.ErrorContains(t, err, fmt.Sprintf("failed to load consul: failed to decode value of %s/key: ", prefix))
+		})
+		t.Run("fail if key has both value and nested keys", func(t *testing.T) {
+			mock, srv := startConsul(t)
+			prefix := gofakeit.Word()
+			mock.set(prefix+"/server", encoded(gofakeit.Word()))
+			mock.set(prefix+"/server/port", encoded("8080"))
+			_, err := loadFromOpts(prefix, WithAddress(srv.URL))
+			assert.ErrorContains(t, err, fmt.Sprintf("failed to load consul: failed to set value of %s/server", prefix))
+		})
+		t.Run("define typed values", func(t *testing.T) {
+			mock, srv := startConsul(t)
+			prefix := gofakeit.Word()
+			mock.set(prefix+"/server/port", encoded("8080"))
+			type serverConfig struct {
+				port int
+			}
+			cfg, err := config.Load(func(p val.Provider) *serverConfig {
+				return &serverConfig{port: val.Define[int](p, "server/port")}
+			}, Load(prefix, WithAddress(srv.URL)))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, &serverConfig{port: 8080}, cfg)
+		})
+	})
+
+	t.Run("Watch", func(t *testing.T) {
+		watch := func(t *testing.T, source config.Source) <-chan struct{} {
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			notified := make(chan struct{}, 1)
+			go source.(config.Watchable).Watch(ctx, func() {
+				select {
+				case notified <- struct{}{}:
+				default:
+				}
+			})
+			return notified
+		}
+		assertNotified := func(t *testing.T, notified <-chan struct{}) {
+			select {
+			case <-notified:
+			case <-time.After(time.Second):
+				assert.Fail(t, "change not notified")
+			}
+		}
+		t.Run("poll for changes", func(t *testing.T) {
+			mock, srv := startConsul(t)
+			prefix := gofakeit.Word()
+			mock.set(prefix+"/key", encoded(gofakeit.SentenceSimple()))
+			source, err := load(prefix, WithAddress(srv.URL), WithPollInterval(time.Millisecond))
+			if !assert.NoError(t, err) {
+				return
+			}
+			notified := watch(t, source)
+			time.Sleep(20 * time.Millisecond)
+			select {
+			case <-notified:
+				assert.Fail(t, "notified without changes")
+				return
+			default:
+			}
+			mock.set(prefix+"/key", encoded(gofakeit.SentenceSimple()))
+			assertNotified(t, notified)
+		})
+		t.Run("use default interval if not positive", func(t *testing.T) {
+			mock, srv := startConsul(t)
+			prefix := gofakeit.Word()
+			mock.set(prefix+"/key", encoded(gofakeit.SentenceSimple()))
+			source, err := load(prefix, WithAddress(srv.URL), WithPollInterval(0))
+			if !assert.NoError(t, err) {
+				return
+			}
+			watch(t, source)
+			time.Sleep(20 * time.Millisecond)
+			assert.Equal(t, 1, mock.requestsCount(), "keys polled without interval")
+		})
+		t.Run("use blocking queries", func(t *testing.T) {
+			mock, srv := startConsul(t)
+			prefix := gofakeit.Word()
+			mock.set(prefix+"/key", encoded(gofakeit.SentenceSimple()))
+			source, err := load(prefix,
+				WithAddress(srv.URL),
+				WithPollInterval(time.Hour),
+				WithBlockingQueries(time.Minute),
+			)
+			if !assert.NoError(t, err) {
+				return
+			}
+			notified := watch(t, source)
+			assert.Eventually(t, func() bool {
+				return mock.requestsCount() == 2
+			}, time.Second, time.Millisecond)
+			query := mock.lastRequest().URL.Query()
+			assert.Equal(t, strconv.FormatUint(mock.currentIndex(), 10), query.Get("index"))
+			assert.Equal(t, "1m0s", query.Get("wait"))
+			mock.set(prefix+"/key", encoded(gofakeit.SentenceSimple()))
+			assertNotified(t, notified)
+		})
+	})
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestSetValue(t *testing.T) {
+	t.Run("set nested values", func(t *testing.T) {
+		values := map[string]interface{}{}
+		assert.NoError(t, setValue(values, "server/host", "localhost"))
+		assert.NoError(t, setValue(values, "server/port", "8080"))
+		assert.Equal(t, map[string]interface{}{
+			"server": map[string]interface{}{"host": "localhost", "port": "8080"},
+		}, values)
+	})
+	t.Run("fail if parent has a value", func(t *testing.T) {
+		values := map[string]interface{}{}
+		assert.NoError(t, setValue(values, "app/server", gofakeit.Word()))
+		assert.EqualError(t, setValue(values, "app/server/port", "8080"), "app/server has a value and can't have nested keys")
+	})
+	t.Run("fail if key has nested keys", func(t *testing.T) {
+		values := map[string]interface{}{}
+		assert.NoError(t, setValue(values, "app/server/port", "8080"))
+		assert.EqualError(t, setValue(values, "app/server", gofakeit.Word()), "app/server has nested keys and can't have a value")
+	})
+}
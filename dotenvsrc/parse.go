@@ -0,0 +1,257 @@
+package dotenvsrc
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+type parser struct {
+	data string
+	pos  int
+	line int
+
+	// vars parsed so far, used for expansion
+	vars map[string]string
+}
+
+func isKeyChar(c byte) bool {
+	return c == '_' || c == '.' || c == '-' ||
+		(c >= 'a' && c <= 'z') ||
+		(c >= 'A' && c <= 'Z') ||
+		(c >= '0' && c <= '9')
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+func (p *parser) skipSpaces() {
+	for !p.eof() && isSpace(p.data[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *parser) skipLine() {
+	for !p.eof() && p.data[p.pos] != '\n' {
+		p.pos++
+	}
+	if !p.eof() {
+		p.pos++
+	}
+	p.line++
+}
+
+// lookup returns value of a variable defined earlier in the file
+// or in the process environment. The environment is never modified.
+func (p *parser) lookup(name string) (string, bool) {
+	if v, ok := p.vars[name]; ok {
+		return v, true
+	}
+	return os.LookupEnv(name)
+}
+
+// expandBraced expands ${VAR} or ${VAR:-default} reference at the start of ref
+// and returns its length, false is returned if the reference is unterminated
+func (p *parser) expandBraced(ref string) (string, int, bool) {
+	end := strings.IndexByte(ref, '}')
+	if end < 0 {
+		return "", 0, false
+	}
+	name, defaultVal, hasDefault := strings.Cut(ref[2:end], ":-")
+	v, ok := p.lookup(name)
+	if (!ok || v == "") && hasDefault {
+		v = defaultVal
+	}
+	return v, end + 1, true
+}
+
+// expandName expands $VAR reference at the start of ref and returns
+// its length, $ is kept as is if it's not followed by a variable name
+func (p *parser) expandName(ref string) (string, int) {
+	end := 1
+	for end < len(ref) && isKeyChar(ref[end]) && ref[end] != '.' && ref[end] != '-' {
+		end++
+	}
+	if end == 1 {
+		return "$", 1
+	}
+	v, _ := p.lookup(ref[1:end])
+	return v, end
+}
+
+// expand replaces ${VAR}, ${VAR:-default} and $VAR references, $$ is replaced with $
+func (p *parser) expand(value string) (string, error) {
+	var result strings.Builder
+	for i := 0; i < len(value); {
+		ref := value[i:]
+		switch {
+		case strings.HasPrefix(ref, "$$"):
+			result.WriteByte('$')
+			i += 2
+		case strings.HasPrefix(ref, "${"):
+			v, n, ok := p.expandBraced(ref)
+			if !ok {
+				return "", p.errorf("unterminated variable reference in %q", value)
+			}
+			result.WriteString(v)
+			i += n
+		case ref[0] == '$':
+			v, n := p.expandName(ref)
+			result.WriteString(v)
+			i += n
+		default:
+			result.WriteByte(ref[0])
+			i++
+		}
+	}
+	return result.String(), nil
+}
+
+func (p *parser) parseKey() (string, error) {
+	const exportPrefix = "export"
+	if rest := p.data[p.pos:]; strings.HasPrefix(rest, exportPrefix) &&
+		len(rest) > len(exportPrefix) && isSpace(rest[len(exportPrefix)]) {
+		p.pos += len(exportPrefix)
+		p.skipSpaces()
+	}
+	start := p.pos
+	for !p.eof() && isKeyChar(p.data[p.pos]) {
+		p.pos++
+	}
+	key := p.data[start:p.pos]
+	if key == "" {
+		return "", p.errorf("invalid variable name")
+	}
+	p.skipSpaces()
+	if p.eof() || p.data[p.pos] != '=' {
+		return "", p.errorf("expected = after %s", key)
+	}
+	p.pos++
+	p.skipSpaces()
+	return key, nil
+}
+
+// writeEscaped writes a character escaped in a double quoted value
+func (p *parser) writeEscaped(result *strings.Builder) {
+	escaped := p.data[p.pos]
+	p.pos++
+	switch escaped {
+	case 'n':
+		result.WriteByte('\n')
+	case 'r':
+		result.WriteByte('\r')
+	case 't':
+		result.WriteByte('\t')
+	case '$':
+		// Keep escaped $ out of expansion
+		result.WriteString("$$")
+	default:
+		result.WriteByte(escaped)
+	}
+}
+
+// endQuoted checks that only a comment follows the closing quote
+// and expands double quoted values
+func (p *parser) endQuoted(quote byte, value string) (string, error) {
+	p.skipSpaces()
+	if !p.eof() && p.data[p.pos] != '\n' && p.data[p.pos] != '#' && p.data[p.pos] != '\r' {
+		return "", p.errorf("unexpected characters after quoted value")
+	}
+	if quote == '"' {
+		return p.expand(value)
+	}
+	return value, nil
+}
+
+// parseQuoted parses a value in quotes that may span multiple lines
+func (p *parser) parseQuoted(quote byte) (string, error) {
+	startLine := p.line
+	p.pos++
+	var result strings.Builder
+	for {
+		if p.eof() {
+			p.line = startLine
+			return "", p.errorf("unterminated quoted value")
+		}
+		c := p.data[p.pos]
+		p.pos++
+		switch {
+		case c == quote:
+			return p.endQuoted(quote, result.String())
+		case c == '\\' && quote == '"' && !p.eof():
+			p.writeEscaped(&result)
+		default:
+			if c == '\n' {
+				p.line++
+			}
+			result.WriteByte(c)
+		}
+	}
+}
+
+func (p *parser) parseUnquoted() (string, error) {
+	start := p.pos
+	for !p.eof() && p.data[p.pos] != '\n' {
+		// Inline comments must be preceded by a space
+		if p.data[p.pos] == '#' && (p.pos == start || p.data[p.pos-1] == ' ' || p.data[p.pos-1] == '\t') {
+			break
+		}
+		p.pos++
+	}
+	return p.expand(strings.TrimSpace(p.data[start:p.pos]))
+}
+
+func (p *parser) parseValue() (string, error) {
+	if p.eof() {
+		return "", nil
+	}
+	switch p.data[p.pos] {
+	case '"', '\'':
+		return p.parseQuoted(p.data[p.pos])
+	default:
+		return p.parseUnquoted()
+	}
+}
+
+// parse parses .env file data. Supported are comments, export prefix,
+// single quoted (literal) and double quoted (escapes and expansion) values
+// that may span multiple lines and ${VAR} expansion in unquoted values.
+// Variables are expanded from values defined earlier in the file
+// or from the process environment.
+func parse(data string) (map[string]string, error) {
+	p := &parser{
+		data: strings.ReplaceAll(data, "\r\n", "\n"),
+		line: 1,
+		vars: map[string]string{},
+	}
+	for !p.eof() {
+		p.skipSpaces()
+		if p.eof() {
+			break
+		}
+		if c := p.data[p.pos]; c == '\n' || c == '#' {
+			p.skipLine()
+			continue
+		}
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		p.vars[key] = value
+		p.skipLine()
+	}
+	return p.vars, nil
+}
@@ -0,0 +1,121 @@
+package dotenvsrc
+
+import (
+	"os"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	processEnv := gofakeit.Generate("TEST_DOTENV_{word}")
+	processVal := gofakeit.Word()
+	os.Setenv(processEnv, processVal)
+	defer os.Unsetenv(processEnv)
+
+	tests := []struct {
+		name    string
+		data    string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name: "plain values",
+			data: "KEY1=val1\nKEY2 = val2 \n\nKEY3=",
+			want: map[string]string{"KEY1": "val1", "KEY2": "val2", "KEY3": ""},
+		},
+		{
+			name: "comments",
+			data: "# comment\nKEY1=val1 # inline comment\n  # indented comment\nKEY2=val#2",
+			want: map[string]string{"KEY1": "val1", "KEY2": "val#2"},
+		},
+		{
+			name: "export prefix",
+			data: "export KEY1=val1\nexport  KEY2=val2\nexport\tKEY3=val3\nexport_KEY4=val4",
+			want: map[string]string{"KEY1": "val1", "KEY2": "val2", "KEY3": "val3", "export_KEY4": "val4"},
+		},
+		{
+			name: "windows line endings",
+			data: "KEY1=val1\r\nKEY2=val2\r\n",
+			want: map[string]string{"KEY1": "val1", "KEY2": "val2"},
+		},
+		{
+			name: "single quoted",
+			data: `KEY1='val 1 # not a comment ${KEY0} \n'`,
+			want: map[string]string{"KEY1": `val 1 # not a comment ${KEY0} \n`},
+		},
+		{
+			name: "double quoted",
+			data: `KEY1="val 1 # not a comment\n\t\"quoted\"" # comment`,
+			want: map[string]string{"KEY1": "val 1 # not a comment\n\t\"quoted\""},
+		},
+		{
+			name: "multi-line",
+			data: "KEY1=\"line 1\nline 2\"\nKEY2='line 3\nline 4'\nKEY3=val3",
+			want: map[string]string{"KEY1": "line 1\nline 2", "KEY2": "line 3\nline 4", "KEY3": "val3"},
+		},
+		{
+			name: "expansion",
+			data: "HOST=example.com\n" +
+				"URL1=https://${HOST}/path\n" +
+				"URL2=\"https://$HOST/path\"\n" +
+				"FROM_ENV=${" + processEnv + "}\n" +
+				"MISSING=${NOT_DEFINED_DOTENV_VAR}\n" +
+				"DEFAULT=${NOT_DEFINED_DOTENV_VAR:-fallback}\n" +
+				"ESCAPED1=$${HOST}\n" +
+				"ESCAPED2=\"\\${HOST}\"\n" +
+				"LITERAL='${HOST}'",
+			want: map[string]string{
+				"HOST":     "example.com",
+				"URL1":     "https://example.com/path",
+				"URL2":     "https://example.com/path",
+				"FROM_ENV": processVal,
+				"MISSING":  "",
+				"DEFAULT":  "fallback",
+				"ESCAPED1": "${HOST}",
+				"ESCAPED2": "${HOST}",
+				"LITERAL":  "${HOST}",
+			},
+		},
+		{
+			name:    "invalid name",
+			data:    "KEY1=val1\n=val2",
+			wantErr: "line 2: invalid variable name",
+		},
+		{
+			name:    "missing =",
+			data:    "KEY1=val1\nKEY2 val2",
+			wantErr: "line 2: expected = after KEY2",
+		},
+		{
+			name:    "unterminated quote",
+			data:    "KEY1=val1\nKEY2=\"val2\n\n",
+			wantErr: "line 2: unterminated quoted value",
+		},
+		{
+			name:    "characters after quote",
+			data:    "KEY1='val1' val2",
+			wantErr: "line 1: unexpected characters after quoted value",
+		},
+		{
+			name:    "unterminated reference",
+			data:    "KEY1=${KEY0",
+			wantErr: "line 1: unterminated variable reference in \"${KEY0\"",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parse(tt.data)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package dotenvsrc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/gocombo/config"
+	"github.com/gocombo/config/internal/poll"
+	"github.com/gocombo/config/val"
+)
+
+type sourceOpts struct {
+	name              string
+	baseDir           string
+	ignoreMissingFile bool
+	pollInterval      time.Duration
+	keyToEnvName      map[string]string
+}
+
+type SourceOpt func(opts *sourceOpts)
+
+// WithName sets a name of the source that is used in errors
+// and value origin. Path of the file is used by default.
+func WithName(name string) SourceOpt {
+	return func(opts *sourceOpts) {
+		opts.name = name
+	}
+}
+
+func WithBaseDir(baseDir string) SourceOpt {
+	return func(opts *sourceOpts) {
+		opts.baseDir = baseDir
+	}
+}
+
+func IgnoreMissingFile() SourceOpt {
+	return func(opts *sourceOpts) {
+		opts.ignoreMissingFile = true
+	}
+}
+
+// WithPollInterval defines how often the file is checked for changes
+// when config is watched
+func WithPollInterval(interval time.Duration) SourceOpt {
+	return func(opts *sourceOpts) {
+		opts.pollInterval = interval
+	}
+}
+
+type LoadValOptBuilder struct {
+	valuePath string
+}
+
+// From defines a variable of the .env file to set value from
+func (b *LoadValOptBuilder) From(envName string) SourceOpt {
+	return func(opts *sourceOpts) {
+		opts.keyToEnvName[b.valuePath] = envName
+	}
+}
+
+// Set config value using From
+func Set(path string) *LoadValOptBuilder {
+	return &LoadValOptBuilder{
+		valuePath: path,
+	}
+}
+
+func newSourceOpts(optSetters []SourceOpt) *sourceOpts {
+	opts := &sourceOpts{
+		pollInterval: poll.DefaultInterval,
+		keyToEnvName: map[string]string{},
+	}
+	for _, optSetter := range optSetters {
+		optSetter(opts)
+	}
+	return opts
+}
+
+func (opts *sourceOpts) sourceName(filePath string) string {
+	if opts.name != "" {
+		return opts.name
+	}
+	return filePath
+}
+
+type source struct {
+	name         string
+	filePath     string
+	pollInterval time.Duration
+	valuesByKey  map[string]val.Raw
+}
+
+func (s *source) Name() string {
+	return s.name
+}
+
+// Watch polls the file for changes
+func (s *source) Watch(ctx context.Context, notify func()) {
+	poll.Files(ctx, s.pollInterval, notify, s.filePath)
+}
+
+func (s *source) GetValue(key string) (val.Raw, bool) {
+	rawVal, ok := s.valuesByKey[key]
+	if !ok {
+		return val.Raw{}, false
+	}
+	return rawVal, true
+}
+
+// Load adds .env file source. Values are mapped from file variables
+// using Set(path).From(name). The process environment is never modified.
+func Load(fileName string, optSetters ...SourceOpt) config.LoadOpt {
+	return func(opts config.LoadOpts) {
+		srcOpts := newSourceOpts(optSetters)
+		name := srcOpts.sourceName(path.Join(srcOpts.baseDir, fileName))
+		opts.AddSourceLoader(config.NamedSourceLoader(name, func() (config.Source, error) {
+			return load(fileName, optSetters...)
+		}))
+	}
+}
+
+func load(fileName string, optSetters ...SourceOpt) (config.Source, error) {
+	opts := newSourceOpts(optSetters)
+	filePath := path.Join(opts.baseDir, fileName)
+	src := &source{
+		name:         opts.sourceName(filePath),
+		filePath:     filePath,
+		pollInterval: opts.pollInterval,
+		valuesByKey:  make(map[string]val.Raw),
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if opts.ignoreMissingFile && os.IsNotExist(err) {
+			return src, nil
+		}
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	vars, err := parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+	for key, envName := range opts.keyToEnvName {
+		envVal, ok := vars[envName]
+		if !ok {
+			continue
+		}
+		src.valuesByKey[key] = val.Raw{
+			Key: key,
+			Val: envVal,
+			Origin: val.Origin{
+				Source: src.name,
+				File:   filePath,
+				EnvVar: envName,
+			},
+		}
+	}
+	return src, nil
+}
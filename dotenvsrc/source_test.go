@@ -0,0 +1,144 @@
+package dotenvsrc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/gocombo/config"
+	"github.com/gocombo/config/val"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockLoadOpts struct {
+	sourceLoaders []config.SourceLoader
+}
+
+func (m *mockLoadOpts) AddSourceLoader(loader config.SourceLoader) {
+	m.sourceLoaders = append(m.sourceLoaders, loader)
+}
+
+func writeFile(t *testing.T, filePath, data string) {
+	if err := os.WriteFile(filePath, []byte(data), 0o644); !assert.NoError(t, err) {
+		t.FailNow()
+	}
+}
+
+func Test_DotEnvSource(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	loadFromOpts := func(fileName string, optsSetters ...SourceOpt) (config.Source, error) {
+		mockOpts := &mockLoadOpts{}
+		loadOpt := Load(fileName, optsSetters...)
+		loadOpt(mockOpts)
+		if len(mockOpts.sourceLoaders) < 1 {
+			return nil, fmt.Errorf("no source loader added to opts")
+		}
+		return mockOpts.sourceLoaders[0]()
+	}
+	t.Run("read configured values from the file", func(t *testing.T) {
+		fileName := gofakeit.Generate("{word}.env")
+		env1 := gofakeit.Generate("TEST_ENV_1_{word}")
+		env2 := gofakeit.Generate("TEST_ENV_2_{word}")
+		path1 := gofakeit.Generate("test/path-1/{word}")
+		path2 := gofakeit.Generate("test/path-2/{word}")
+		path3 := gofakeit.Generate("test/path-3/{word}")
+		val1 := gofakeit.Word()
+		val2 := gofakeit.Word()
+		writeFile(t, filepath.Join(tmpDir, fileName), fmt.Sprintf("%s=%s\nexport %s='%s'\n", env1, val1, env2, val2))
+		source, err := loadFromOpts(fileName,
+			WithBaseDir(tmpDir),
+			Set(path1).From(env1),
+			Set(path2).From(env2),
+			Set(path3).From(gofakeit.Generate("TEST_ENV_3_{word}")),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		got1, ok := source.GetValue(path1)
+		if assert.True(t, ok, "key %s not found", path1) {
+			assert.Equal(t, val.Raw{
+				Key: path1,
+				Val: val1,
+				Origin: val.Origin{
+					Source: filepath.Join(tmpDir, fileName),
+					File:   filepath.Join(tmpDir, fileName),
+					EnvVar: env1,
+				},
+			}, got1)
+		}
+		got2, ok := source.GetValue(path2)
+		if assert.True(t, ok, "key %s not found", path2) {
+			assert.Equal(t, val2, got2.Val)
+		}
+		_, ok = source.GetValue(path3)
+		assert.False(t, ok, "key %s found", path3)
+	})
+	t.Run("not modify process environment", func(t *testing.T) {
+		fileName := gofakeit.Generate("{word}.env")
+		env1 := gofakeit.Generate("TEST_ENV_1_{word}")
+		writeFile(t, filepath.Join(tmpDir, fileName), fmt.Sprintf("%s=%s\n", env1, gofakeit.Word()))
+		_, err := loadFromOpts(fileName, WithBaseDir(tmpDir), Set("path").From(env1))
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, ok := os.LookupEnv(env1)
+		assert.False(t, ok)
+	})
+	t.Run("fail if missing", func(t *testing.T) {
+		fileName := gofakeit.Generate("{word}.env")
+		_, err := loadFromOpts(fileName, WithBaseDir(tmpDir))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		assert.Contains(t, err.Error(), fmt.Sprintf("failed to load %s: ", filepath.Join(tmpDir, fileName)))
+	})
+	t.Run("ignore missing file", func(t *testing.T) {
+		fileName := gofakeit.Generate("{word}.env")
+		path1 := gofakeit.Generate("test/path-1/{word}")
+		source, err := loadFromOpts(fileName, WithBaseDir(tmpDir), IgnoreMissingFile(), Set(path1).From("ENV1"))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, filepath.Join(tmpDir, fileName), source.(config.Named).Name())
+		_, ok := source.GetValue(path1)
+		assert.False(t, ok, "key %s found", path1)
+	})
+	t.Run("fail if invalid file", func(t *testing.T) {
+		fileName := gofakeit.Generate("{word}.env")
+		name := gofakeit.Word()
+		writeFile(t, filepath.Join(tmpDir, fileName), "KEY1=val1\nnot valid")
+		_, err := loadFromOpts(fileName, WithBaseDir(tmpDir), WithName(name))
+		assert.EqualError(t, err, fmt.Sprintf("failed to load %s: failed to parse file: line 2: expected = after not", name))
+	})
+	t.Run("notify when file changes", func(t *testing.T) {
+		fileName := gofakeit.Generate("{word}.env")
+		writeFile(t, filepath.Join(tmpDir, fileName), "KEY1="+gofakeit.Word())
+		source, err := load(fileName, WithBaseDir(tmpDir), WithPollInterval(time.Millisecond))
+		if !assert.NoError(t, err) {
+			return
+		}
+		watchable, ok := source.(config.Watchable)
+		if !assert.True(t, ok, "source is not watchable") {
+			return
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		notified := make(chan struct{}, 1)
+		go watchable.Watch(ctx, func() {
+			select {
+			case notified <- struct{}{}:
+			default:
+			}
+		})
+		time.Sleep(20 * time.Millisecond)
+		writeFile(t, filepath.Join(tmpDir, fileName), "KEY1="+gofakeit.Word()+"-changed")
+		select {
+		case <-notified:
+		case <-time.After(time.Second):
+			assert.Fail(t, "change not notified")
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package envsrc
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultFileSuffix is a suffix of variables that point
+// to a file with the value, e.g DB_PASSWORD_FILE
+const DefaultFileSuffix = "_FILE"
+
+// WithFileIndirection makes the source also check a variable with
+// DefaultFileSuffix and read the value from the file it points to,
+// e.g DB_PASSWORD is read from the file set in DB_PASSWORD_FILE.
+// Trailing line breaks of the file content are removed.
+// It's an error if both variables are set.
+func WithFileIndirection() SourceOpt {
+	return WithFileSuffix(DefaultFileSuffix)
+}
+
+// WithFileSuffix is same as WithFileIndirection but uses given suffix
+func WithFileSuffix(suffix string) SourceOpt {
+	return func(opts *sourceOpts) {
+		opts.fileSuffix = suffix
+	}
+}
+
+type envValue struct {
+	envName  string
+	filePath string
+	value    string
+}
+
+// resolveEnv looks up the variable, and if file suffix is
+// set, a variable pointing to the file with the value
+func resolveEnv(lookupEnv func(string) (string, bool), envName, fileSuffix string) (envValue, bool, error) {
+	value, ok := lookupEnv(envName)
+	if fileSuffix == "" {
+		return envValue{envName: envName, value: value}, ok, nil
+	}
+	fileEnvName := envName + fileSuffix
+	filePath, fileOk := lookupEnv(fileEnvName)
+	switch {
+	case !fileOk:
+		return envValue{envName: envName, value: value}, ok, nil
+	case ok:
+		return envValue{}, false, fmt.Errorf("both %s and %s are set", envName, fileEnvName)
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return envValue{}, false, fmt.Errorf("failed to read %s: %w", fileEnvName, err)
+	}
+	return envValue{
+		envName:  fileEnvName,
+		filePath: filePath,
+		value:    strings.TrimRight(string(data), "\r\n"),
+	}, true, nil
+}
@@ -0,0 +1,135 @@
+package envsrc
+
+import (
+	"os"
+	"strings"
+	"unicode"
+)
+
+// CaseFolding converts a single key segment to the
+// form it has in environment variable names
+type CaseFolding func(segment string) string
+
+// UpperSnakeCase converts camelCase, kebab-case and dot.case
+// segments to UPPER_SNAKE_CASE, e.g maxConnections to MAX_CONNECTIONS.
+// This is the default case folding of LoadPrefixed.
+func UpperSnakeCase(segment string) string {
+	runes := []rune(segment)
+	var b strings.Builder
+	for i, r := range runes {
+		if r == '-' || r == '.' {
+			b.WriteRune('_')
+			continue
+		}
+		if startsWord(runes, i) {
+			b.WriteRune('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// startsWord reports if the upper case rune at i starts a new word,
+// e.g C in maxConnections or P in HTTPPort
+func startsWord(runes []rune, i int) bool {
+	if i == 0 || !unicode.IsUpper(runes[i]) {
+		return false
+	}
+	prev := runes[i-1]
+	nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+	return unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower)
+}
+
+// UpperCase converts the segment to upper case keeping
+// everything else as is, e.g maxConnections to MAXCONNECTIONS
+func UpperCase(segment string) string {
+	return strings.ToUpper(segment)
+}
+
+// WithSeparator sets separators that are placed between key segments
+// when looking up environment variables in LoadPrefixed mode.
+// Separators are tried in the given order. Default is "__" then "_".
+func WithSeparator(separators ...string) SourceOpt {
+	return func(opts *sourceOpts) {
+		opts.separators = separators
+	}
+}
+
+// WithCaseFolding sets how key segments are converted when looking up
+// environment variables in LoadPrefixed mode. Default is UpperSnakeCase.
+func WithCaseFolding(caseFolding CaseFolding) SourceOpt {
+	return func(opts *sourceOpts) {
+		opts.caseFolding = caseFolding
+	}
+}
+
+func withPrefix(prefix string) SourceOpt {
+	return func(opts *sourceOpts) {
+		opts.prefixed = true
+		opts.prefix = prefix
+	}
+}
+
+type prefixedLookup struct {
+	prefix      string
+	separators  []string
+	caseFolding CaseFolding
+
+	// environ is a snapshot of environment variables taken at load time
+	environ map[string]envValue
+}
+
+func newPrefixedLookup(opts *sourceOpts) (*prefixedLookup, error) {
+	snapshot := make(map[string]string)
+	for _, kv := range os.Environ() {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, opts.prefix) {
+			continue
+		}
+		snapshot[name] = value
+	}
+	environ := make(map[string]envValue, len(snapshot))
+	for name, value := range snapshot {
+		environ[name] = envValue{envName: name, value: value}
+	}
+	if opts.fileSuffix != "" {
+		lookupSnapshot := func(name string) (string, bool) {
+			value, ok := snapshot[name]
+			return value, ok
+		}
+		for name := range snapshot {
+			envName, ok := strings.CutSuffix(name, opts.fileSuffix)
+			if !ok || envName == "" {
+				continue
+			}
+			resolved, _, err := resolveEnv(lookupSnapshot, envName, opts.fileSuffix)
+			if err != nil {
+				return nil, err
+			}
+			environ[envName] = resolved
+		}
+	}
+	return &prefixedLookup{
+		prefix:      opts.prefix,
+		separators:  opts.separators,
+		caseFolding: opts.caseFolding,
+		environ:     environ,
+	}, nil
+}
+
+func (l *prefixedLookup) lookup(key string) (envValue, bool) {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = l.caseFolding(segment)
+	}
+	for _, separator := range l.separators {
+		envName := strings.Join(segments, separator)
+		if l.prefix != "" {
+			envName = l.prefix + "_" + envName
+		}
+		if value, ok := l.environ[envName]; ok {
+			return value, true
+		}
+	}
+	return envValue{}, false
+}
@@ -1,6 +1,7 @@
 package envsrc
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/gocombo/config"
@@ -8,11 +9,27 @@ import (
 )
 
 type sourceOpts struct {
+	name         string
 	keyToEnvName map[string]string
+
+	prefixed    bool
+	prefix      string
+	separators  []string
+	caseFolding CaseFolding
+
+	fileSuffix string
 }
 
 type SourceOpt func(opts *sourceOpts)
 
+// WithName sets a name of the source that is used in errors
+// and value origin. Default name is "env".
+func WithName(name string) SourceOpt {
+	return func(opts *sourceOpts) {
+		opts.name = name
+	}
+}
+
 type LoadValOptBuilder struct {
 	valuePath string
 }
@@ -30,44 +47,100 @@ func Set(path string) *LoadValOptBuilder {
 }
 
 type source struct {
-	valuesByKey map[string]val.Raw
+	name         string
+	keyToEnvName map[string]string
+	valuesByKey  map[string]val.Raw
+
+	// prefixed is used to lookup keys that are not explicitly
+	// mapped if the source was loaded with LoadPrefixed
+	prefixed *prefixedLookup
+}
+
+func (s *source) Name() string {
+	return s.name
 }
 
 func (s *source) GetValue(key string) (val.Raw, bool) {
-	rawVal, ok := s.valuesByKey[key]
+	if _, ok := s.keyToEnvName[key]; ok || s.prefixed == nil {
+		rawVal, ok := s.valuesByKey[key]
+		if !ok {
+			return val.Raw{}, false
+		}
+		return rawVal, true
+	}
+	value, ok := s.prefixed.lookup(key)
 	if !ok {
 		return val.Raw{}, false
 	}
-	return rawVal, true
+	return s.newRaw(key, value), true
+}
+
+func (s *source) newRaw(key string, value envValue) val.Raw {
+	return val.Raw{
+		Key: key,
+		Val: value.value,
+		Origin: val.Origin{
+			Source: s.name,
+			EnvVar: value.envName,
+			File:   value.filePath,
+		},
+	}
 }
 
 func Load(optSetters ...SourceOpt) config.LoadOpt {
 	return func(opts config.LoadOpts) {
-		opts.AddSourceLoader(func() (config.Source, error) {
-			return load(optSetters...), nil
-		})
+		name := newSourceOpts(optSetters).name
+		opts.AddSourceLoader(config.NamedSourceLoader(name, func() (config.Source, error) {
+			return load(optSetters...)
+		}))
 	}
 }
 
-func load(optSetters ...SourceOpt) config.Source {
+// LoadPrefixed loads values from environment variables that start with the prefix
+// without the need to map each key explicitly. Key segments are converted with
+// the case folding and joined with the separator, so server/port is read from
+// MYAPP_SERVER__PORT or MYAPP_SERVER_PORT if the prefix is MYAPP.
+// Keys mapped with Set take precedence over automatic mapping.
+func LoadPrefixed(prefix string, optSetters ...SourceOpt) config.LoadOpt {
+	return Load(append([]SourceOpt{withPrefix(prefix)}, optSetters...)...)
+}
+
+func newSourceOpts(optSetters []SourceOpt) *sourceOpts {
 	opts := &sourceOpts{
+		name:         "env",
 		keyToEnvName: map[string]string{},
+		separators:   []string{"__", "_"},
+		caseFolding:  UpperSnakeCase,
 	}
 	for _, optSetter := range optSetters {
 		optSetter(opts)
 	}
+	return opts
+}
+
+func load(optSetters ...SourceOpt) (config.Source, error) {
+	opts := newSourceOpts(optSetters)
 	src := &source{
-		valuesByKey: make(map[string]val.Raw),
+		name:         opts.name,
+		keyToEnvName: opts.keyToEnvName,
+		valuesByKey:  make(map[string]val.Raw),
+	}
+	if opts.prefixed {
+		prefixed, err := newPrefixedLookup(opts)
+		if err != nil {
+			return nil, err
+		}
+		src.prefixed = prefixed
 	}
 	for key, env := range opts.keyToEnvName {
-		envVal, ok := os.LookupEnv(env)
+		value, ok, err := resolveEnv(os.LookupEnv, env, opts.fileSuffix)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", key, err)
+		}
 		if !ok {
 			continue
 		}
-		src.valuesByKey[key] = val.Raw{
-			Key: key,
-			Val: envVal,
-		}
+		src.valuesByKey[key] = src.newRaw(key, value)
 	}
-	return src
+	return src, nil
 }
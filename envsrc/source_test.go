@@ -3,10 +3,13 @@ package envsrc
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/brianvoe/gofakeit/v6"
 	"github.com/gocombo/config"
+	"github.com/gocombo/config/val"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -61,6 +64,43 @@ func Test_EnvSource(t *testing.T) {
 		assertVal(t, source, path1, val1)
 		assertVal(t, source, path2, val2)
 	})
+	t.Run("set value origin", func(t *testing.T) {
+		env1 := gofakeit.Generate("TEST_ENV_1_{word}")
+		path1 := gofakeit.Generate("test/path-1/{word}")
+		os.Setenv(env1, gofakeit.SentenceSimple())
+		defer os.Unsetenv(env1)
+		source, err := loadFromOpts(
+			Set(path1).From(env1),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		got, ok := source.GetValue(path1)
+		if !assert.True(t, ok, "key %s not found", path1) {
+			return
+		}
+		assert.Equal(t, val.Origin{Source: "env", EnvVar: env1}, got.Origin)
+	})
+	t.Run("use source name", func(t *testing.T) {
+		env1 := gofakeit.Generate("TEST_ENV_1_{word}")
+		path1 := gofakeit.Generate("test/path-1/{word}")
+		name := gofakeit.Word()
+		os.Setenv(env1, gofakeit.SentenceSimple())
+		defer os.Unsetenv(env1)
+		source, err := loadFromOpts(
+			Set(path1).From(env1),
+			WithName(name),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, name, source.(config.Named).Name())
+		got, ok := source.GetValue(path1)
+		if !assert.True(t, ok, "key %s not found", path1) {
+			return
+		}
+		assert.Equal(t, name, got.Origin.Source)
+	})
 	t.Run("handle empty values", func(t *testing.T) {
 		env1 := gofakeit.Generate("TEST_ENV_1_{word}")
 		env2 := gofakeit.Generate("TEST_ENV_1_{word}")
@@ -98,4 +138,194 @@ func Test_EnvSource(t *testing.T) {
 		}
 		assertVal(t, source, path1, val1)
 	})
+	t.Run("file indirection", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		writeFile := func(t *testing.T, content string) string {
+			filePath := filepath.Join(tmpDir, gofakeit.Generate("secret-{word}"))
+			if err := os.WriteFile(filePath, []byte(content), 0o600); !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			return filePath
+		}
+		t.Run("read value from file", func(t *testing.T) {
+			env1 := gofakeit.Generate("TEST_ENV_1_{word}")
+			path1 := gofakeit.Generate("test/path-1/{word}")
+			val1 := gofakeit.SentenceSimple()
+			filePath := writeFile(t, val1+"\n")
+			t.Setenv(env1+"_FILE", filePath)
+			source, err := loadFromOpts(Set(path1).From(env1), WithFileIndirection())
+			if !assert.NoError(t, err) {
+				return
+			}
+			got, ok := source.GetValue(path1)
+			if !assert.True(t, ok, "key %s not found", path1) {
+				return
+			}
+			assert.Equal(t, val.Raw{
+				Key: path1,
+				Val: val1,
+				Origin: val.Origin{
+					Source: "env",
+					EnvVar: env1 + "_FILE",
+					File:   filePath,
+				},
+			}, got)
+		})
+		t.Run("read value from env if no file", func(t *testing.T) {
+			env1 := gofakeit.Generate("TEST_ENV_1_{word}")
+			path1 := gofakeit.Generate("test/path-1/{word}")
+			val1 := gofakeit.SentenceSimple()
+			t.Setenv(env1, val1)
+			source, err := loadFromOpts(Set(path1).From(env1), WithFileIndirection())
+			if !assert.NoError(t, err) {
+				return
+			}
+			assertVal(t, source, path1, val1)
+		})
+		t.Run("use custom suffix", func(t *testing.T) {
+			env1 := gofakeit.Generate("TEST_ENV_1_{word}")
+			path1 := gofakeit.Generate("test/path-1/{word}")
+			val1 := gofakeit.SentenceSimple()
+			t.Setenv(env1+"_PATH", writeFile(t, val1))
+			t.Setenv(env1+"_FILE", writeFile(t, gofakeit.SentenceSimple()))
+			source, err := loadFromOpts(Set(path1).From(env1), WithFileSuffix("_PATH"))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assertVal(t, source, path1, val1)
+		})
+		t.Run("ignore file if not enabled", func(t *testing.T) {
+			env1 := gofakeit.Generate("TEST_ENV_1_{word}")
+			path1 := gofakeit.Generate("test/path-1/{word}")
+			t.Setenv(env1+"_FILE", writeFile(t, gofakeit.SentenceSimple()))
+			source, err := loadFromOpts(Set(path1).From(env1))
+			if !assert.NoError(t, err) {
+				return
+			}
+			_, ok := source.GetValue(path1)
+			assert.False(t, ok, "key %s found", path1)
+		})
+		t.Run("fail if both set", func(t *testing.T) {
+			env1 := gofakeit.Generate("TEST_ENV_1_{word}")
+			path1 := gofakeit.Generate("test/path-1/{word}")
+			t.Setenv(env1, gofakeit.SentenceSimple())
+			t.Setenv(env1+"_FILE", writeFile(t, gofakeit.SentenceSimple()))
+			_, err := loadFromOpts(Set(path1).From(env1), WithFileIndirection())
+			assert.EqualError(t, err, fmt.Sprintf(
+				"failed to load env: failed to resolve %s: both %s and %s_FILE are set", path1, env1, env1,
+			))
+		})
+		t.Run("fail if file is missing", func(t *testing.T) {
+			env1 := gofakeit.Generate("TEST_ENV_1_{word}")
+			t.Setenv(env1+"_FILE", filepath.Join(tmpDir, gofakeit.Generate("missing-{word}")))
+			_, err := loadFromOpts(Set(gofakeit.Generate("test/path-1/{word}")).From(env1), WithFileIndirection())
+			assert.ErrorIs(t, err, os.ErrNotExist)
+		})
+		t.Run("read prefixed values from file", func(t *testing.T) {
+			prefix := strings.ToUpper(gofakeit.Generate("TEST_{letter}{letter}{letter}{letter}{letter}{letter}"))
+			val1 := gofakeit.SentenceSimple()
+			t.Setenv(prefix+"_DB__PASSWORD_FILE", writeFile(t, val1))
+			source, err := loadFromOpts(withPrefix(prefix), WithFileIndirection())
+			if !assert.NoError(t, err) {
+				return
+			}
+			assertVal(t, source, "db/password", val1)
+		})
+		t.Run("fail if both prefixed set", func(t *testing.T) {
+			prefix := strings.ToUpper(gofakeit.Generate("TEST_{letter}{letter}{letter}{letter}{letter}{letter}"))
+			t.Setenv(prefix+"_DB__PASSWORD", gofakeit.SentenceSimple())
+			t.Setenv(prefix+"_DB__PASSWORD_FILE", writeFile(t, gofakeit.SentenceSimple()))
+			_, err := loadFromOpts(withPrefix(prefix), WithFileIndirection())
+			assert.EqualError(t, err, fmt.Sprintf(
+				"failed to load env: both %[1]s_DB__PASSWORD and %[1]s_DB__PASSWORD_FILE are set", prefix,
+			))
+		})
+	})
+	t.Run("LoadPrefixed", func(t *testing.T) {
+		loadPrefixed := func(prefix string, optsSetters ...SourceOpt) (config.Source, error) {
+			mockOpts := &mockLoadOpts{}
+			loadOpt := LoadPrefixed(prefix, optsSetters...)
+			loadOpt(mockOpts)
+			if len(mockOpts.sourceLoaders) < 1 {
+				return nil, fmt.Errorf("no source loader added to opts")
+			}
+			return mockOpts.sourceLoaders[0]()
+		}
+		randomPrefix := func() string {
+			return strings.ToUpper(gofakeit.Generate("TEST_{letter}{letter}{letter}{letter}{letter}{letter}"))
+		}
+		t.Run("map keys with default separators", func(t *testing.T) {
+			prefix := randomPrefix()
+			val1 := gofakeit.SentenceSimple()
+			val2 := gofakeit.SentenceSimple()
+			t.Setenv(prefix+"_SERVER__PORT", val1)
+			t.Setenv(prefix+"_DB_MAX_CONNECTIONS", val2)
+			source, err := loadPrefixed(prefix)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assertVal(t, source, "server/port", val1)
+			assertVal(t, source, "db/maxConnections", val2)
+			got, ok := source.GetValue("server/port")
+			if assert.True(t, ok) {
+				assert.Equal(t, val.Origin{Source: "env", EnvVar: prefix + "_SERVER__PORT"}, got.Origin)
+			}
+			_, ok = source.GetValue("server/host")
+			assert.False(t, ok)
+		})
+		t.Run("prefer first separator", func(t *testing.T) {
+			prefix := randomPrefix()
+			val1 := gofakeit.SentenceSimple()
+			t.Setenv(prefix+"_SERVER__PORT", val1)
+			t.Setenv(prefix+"_SERVER_PORT", gofakeit.SentenceSimple())
+			source, err := loadPrefixed(prefix)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assertVal(t, source, "server/port", val1)
+		})
+		t.Run("use custom separator and case folding", func(t *testing.T) {
+			prefix := randomPrefix()
+			val1 := gofakeit.SentenceSimple()
+			t.Setenv(prefix+"_SERVER.MAXCONNECTIONS", val1)
+			t.Setenv(prefix+"_SERVER_MAXCONNECTIONS", gofakeit.SentenceSimple())
+			source, err := loadPrefixed(prefix, WithSeparator("."), WithCaseFolding(UpperCase))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assertVal(t, source, "server/maxConnections", val1)
+		})
+		t.Run("prefer explicit mapping", func(t *testing.T) {
+			prefix := randomPrefix()
+			env1 := gofakeit.Generate("TEST_ENV_1_{word}")
+			val1 := gofakeit.SentenceSimple()
+			t.Setenv(env1, val1)
+			t.Setenv(prefix+"_SERVER__PORT", gofakeit.SentenceSimple())
+			t.Setenv(prefix+"_SERVER__HOST", gofakeit.SentenceSimple())
+			source, err := loadPrefixed(prefix,
+				Set("server/port").From(env1),
+				Set("server/host").From(gofakeit.Generate("TEST_ENV_2_{word}")),
+			)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assertVal(t, source, "server/port", val1)
+			_, ok := source.GetValue("server/host")
+			assert.False(t, ok)
+		})
+	})
+}
+
+func TestUpperSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"port":           "PORT",
+		"maxConnections": "MAX_CONNECTIONS",
+		"max-conns":      "MAX_CONNS",
+		"tls.cert":       "TLS_CERT",
+		"HTTPServer":     "HTTP_SERVER",
+		"v2Api":          "V2_API",
+	}
+	for segment, want := range tests {
+		assert.Equal(t, want, UpperSnakeCase(segment), segment)
+	}
 }
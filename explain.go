@@ -0,0 +1,70 @@
+package config
+
+import "github.com/gocombo/config/val"
+
+// KeyExplanation describes how the value of a key was resolved
+type KeyExplanation struct {
+	Key string
+
+	// Found is false if none of the sources had the value
+	// and no default value was used
+	Found bool
+
+	// Value is the raw value that was used to build the config.
+	// If deep merge is enabled it may contain values merged from shadowed sources.
+	// Default values have val.CodeDefaultSource as the origin source.
+	// Values of keys defined as val.Secret are val.Redacted.
+	Value val.Raw
+
+	// Shadowed are values of lower priority sources that were overridden by Value.
+	// Values are ordered by priority, highest first.
+	Shadowed []val.Raw
+}
+
+func (p *valuesProvider) explain(key string) KeyExplanation {
+	explanation := KeyExplanation{Key: key}
+	explanation.Value, explanation.Found = p.lookup(key)
+	if defaultRaw, ok := p.defaults[key]; ok && !explanation.Found {
+		explanation.Value, explanation.Found = defaultRaw, true
+	}
+	winnerSkipped := false
+	for i := range p.sources {
+		src := p.sources[len(p.sources)-1-i]
+		v, ok := src.GetValue(key)
+		if !ok {
+			continue
+		}
+		if !winnerSkipped {
+			winnerSkipped = true
+			continue
+		}
+		explanation.Shadowed = append(explanation.Shadowed, v)
+	}
+	if p.secretKeys[key] {
+		if explanation.Found {
+			explanation.Value.Val = val.Redacted
+		}
+		for i := range explanation.Shadowed {
+			explanation.Shadowed[i].Val = val.Redacted
+		}
+	}
+	return explanation
+}
+
+// Explain loads the config same way as Load does and returns
+// an explanation for every key the factory has requested, in order of request.
+// Explanations are also returned if the config failed to build, along with the error.
+func Explain[T any](factory configFactory[T], optsSetters ...LoadOpt) ([]KeyExplanation, error) {
+	opts := newLoadOpts(optsSetters)
+	sources, err := opts.loadSources()
+	if err != nil {
+		return nil, err
+	}
+	provider := newValuesProvider(sources, opts)
+	_, err = build(factory, provider, opts.validators)
+	explanations := make([]KeyExplanation, len(provider.keys))
+	for i, key := range provider.keys {
+		explanations[i] = provider.explain(key)
+	}
+	return explanations, err
+}
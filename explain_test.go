@@ -0,0 +1,150 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/gocombo/config/val"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	type config struct {
+		val1 string
+		val2 string
+		val3 string
+	}
+	testConfigFactory := func(p val.Provider) *config {
+		return &config{
+			val1: val.Define[string](p, "val1"),
+			val2: val.Define[string](p, "val2"),
+			val3: val.Define[string](p, "val3", val.Optional()),
+		}
+	}
+	withMockSource := func(src *mockKeyValueSource, err error) LoadOpt {
+		return func(opts LoadOpts) {
+			opts.AddSourceLoader(func() (Source, error) {
+				return src, err
+			})
+		}
+	}
+	randomRaw := func(key string) val.Raw {
+		return val.Raw{
+			Key: key,
+			Val: gofakeit.Word(),
+			Origin: val.Origin{
+				Source: gofakeit.Generate("{word}.json"),
+			},
+		}
+	}
+
+	t.Run("explain winning and shadowed values", func(t *testing.T) {
+		src1Val1 := randomRaw("val1")
+		src1Val2 := randomRaw("val2")
+		src2Val1 := randomRaw("val1")
+		src3Val1 := randomRaw("val1")
+		got, err := Explain(
+			testConfigFactory,
+			withMockSource(&mockKeyValueSource{
+				values: map[string]val.Raw{"val1": src1Val1, "val2": src1Val2},
+			}, nil),
+			withMockSource(&mockKeyValueSource{
+				values: map[string]val.Raw{"val1": src2Val1},
+			}, nil),
+			withMockSource(&mockKeyValueSource{
+				values: map[string]val.Raw{"val1": src3Val1},
+			}, nil),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, []KeyExplanation{
+			{Key: "val1", Found: true, Value: src3Val1, Shadowed: []val.Raw{src2Val1, src1Val1}},
+			{Key: "val2", Found: true, Value: src1Val2},
+			{Key: "val3"},
+		}, got)
+	})
+	t.Run("explain if failed to build", func(t *testing.T) {
+		src1Val1 := randomRaw("val1")
+		got, err := Explain(
+			testConfigFactory,
+			withMockSource(&mockKeyValueSource{
+				values: map[string]val.Raw{"val1": src1Val1},
+			}, nil),
+		)
+		var buildErr ErrBuildFailed
+		assert.ErrorAs(t, err, &buildErr)
+		assert.Equal(t, []KeyExplanation{
+			{Key: "val1", Found: true, Value: src1Val1},
+			{Key: "val2"},
+			{Key: "val3"},
+		}, got)
+	})
+	t.Run("explain default values", func(t *testing.T) {
+		wantDefault := gofakeit.Word()
+		got, err := Explain(
+			func(p val.Provider) *config {
+				return &config{
+					val1: val.Define[string](p, "val1", val.Default(wantDefault)),
+				}
+			},
+			withMockSource(&mockKeyValueSource{}, nil),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, []KeyExplanation{
+			{
+				Key:   "val1",
+				Found: true,
+				Value: val.Raw{Key: "val1", Val: wantDefault, Origin: val.Origin{Source: val.CodeDefaultSource}},
+			},
+		}, got)
+	})
+	t.Run("redact secrets", func(t *testing.T) {
+		type dbConfig struct {
+			Password val.Secret[string] `json:"password"`
+		}
+		type secretConfig struct {
+			password val.Secret[string]
+			db       dbConfig
+		}
+		src1Password := randomRaw("password")
+		src2Password := randomRaw("password")
+		src1DB := val.Raw{Key: "db", Val: map[string]interface{}{"password": gofakeit.Word()}}
+		got, err := Explain(
+			func(p val.Provider) *secretConfig {
+				return &secretConfig{
+					password: val.Define[val.Secret[string]](p, "password"),
+					db:       val.Define[dbConfig](p, "db"),
+				}
+			},
+			withMockSource(&mockKeyValueSource{
+				values: map[string]val.Raw{"password": src1Password, "db": src1DB},
+			}, nil),
+			withMockSource(&mockKeyValueSource{
+				values: map[string]val.Raw{"password": src2Password},
+			}, nil),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		redacted := func(raw val.Raw) val.Raw {
+			raw.Val = val.Redacted
+			return raw
+		}
+		assert.Equal(t, []KeyExplanation{
+			{Key: "password", Found: true, Value: redacted(src2Password), Shadowed: []val.Raw{redacted(src1Password)}},
+			{Key: "db", Found: true, Value: redacted(src1DB)},
+		}, got)
+	})
+	t.Run("fail if source failed to load", func(t *testing.T) {
+		wantErr := errors.New(gofakeit.Sentence(3))
+		_, err := Explain(
+			testConfigFactory,
+			withMockSource(&mockKeyValueSource{}, wantErr),
+		)
+		assert.Equal(t, wantErr, err)
+	})
+}
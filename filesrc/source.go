@@ -1,23 +1,55 @@
 package filesrc
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/gocombo/config"
+	"github.com/gocombo/config/internal/poll"
 	"github.com/gocombo/config/val"
 )
 
 type sourceFileOpt struct {
-	filePath      string
-	ignoreMissing bool
+	filePath            string
+	ignoreMissing       bool
+	recursive           bool
+	trimTrailingNewline bool
+
+	// transforms are applied to the content in order
+	transforms []transform
 }
 
 type sourceOpts struct {
+	name            string
 	keyToSourceFile map[string]sourceFileOpt
+	dirs            []sourceFileOpt
+	pollInterval    time.Duration
 }
 
 type SourceOpt func(opts *sourceOpts)
 
+// WithName sets a name of the source that is used in errors
+// and value origin. Default name is "file".
+func WithName(name string) SourceOpt {
+	return func(opts *sourceOpts) {
+		opts.name = name
+	}
+}
+
+// WithPollInterval defines how often files are checked for changes
+// when config is watched
+func WithPollInterval(interval time.Duration) SourceOpt {
+	return func(opts *sourceOpts) {
+		opts.pollInterval = interval
+	}
+}
+
 type LoadValOptBuilder struct {
 	valuePath string
 }
@@ -30,6 +62,39 @@ func IgnoreMissing() FromOpt {
 	}
 }
 
+// Recursive makes Dir read subdirectories too. Keys of the
+// values from subdirectories are joined with "/", e.g db/password.
+// Has no effect on values set with From.
+func Recursive() FromOpt {
+	return func(s *sourceFileOpt) {
+		s.recursive = true
+	}
+}
+
+// TrimTrailingNewline removes trailing line breaks from the file content
+// that are often left by editors or echo when the file is created
+func TrimTrailingNewline() FromOpt {
+	return func(s *sourceFileOpt) {
+		s.trimTrailingNewline = true
+	}
+}
+
+// Dir sets a value for every file in the directory using the file name as a key.
+// This is a layout of Kubernetes secret and config map volumes or /run/secrets.
+// Entries starting with ".." (used by Kubernetes to swap volume content atomically)
+// are skipped, symlinks are followed. Values set with From take precedence.
+func Dir(dirPath string, pathOpts ...FromOpt) SourceOpt {
+	return func(opts *sourceOpts) {
+		s := sourceFileOpt{
+			filePath: dirPath,
+		}
+		for _, pathOpt := range pathOpts {
+			pathOpt(&s)
+		}
+		opts.dirs = append(opts.dirs, s)
+	}
+}
+
 // From defines filePath to set value from
 func (b *LoadValOptBuilder) From(filePath string, pathOpts ...FromOpt) SourceOpt {
 	return func(opts *sourceOpts) {
@@ -51,7 +116,19 @@ func Set(path string) *LoadValOptBuilder {
 }
 
 type source struct {
-	valuesByKey map[string]val.Raw
+	name         string
+	filePaths    []string
+	pollInterval time.Duration
+	valuesByKey  map[string]val.Raw
+}
+
+func (s *source) Name() string {
+	return s.name
+}
+
+// Watch polls all configured files for changes
+func (s *source) Watch(ctx context.Context, notify func()) {
+	poll.Files(ctx, s.pollInterval, notify, s.filePaths...)
 }
 
 func (s *source) GetValue(key string) (val.Raw, bool) {
@@ -62,36 +139,124 @@ func (s *source) GetValue(key string) (val.Raw, bool) {
 	return rawVal, true
 }
 
+func newSourceOpts(optSetters []SourceOpt) *sourceOpts {
+	opts := &sourceOpts{
+		name:            "file",
+		keyToSourceFile: map[string]sourceFileOpt{},
+		pollInterval:    poll.DefaultInterval,
+	}
+	for _, optSetter := range optSetters {
+		optSetter(opts)
+	}
+	return opts
+}
+
 func Load(optSetters ...SourceOpt) config.LoadOpt {
 	return func(opts config.LoadOpts) {
-		opts.AddSourceLoader(func() (config.Source, error) {
+		name := newSourceOpts(optSetters).name
+		opts.AddSourceLoader(config.NamedSourceLoader(name, func() (config.Source, error) {
 			return load(optSetters...)
-		})
+		}))
 	}
 }
 
-func load(optSetters ...SourceOpt) (config.Source, error) {
-	opts := &sourceOpts{
-		keyToSourceFile: map[string]sourceFileOpt{},
+func (s *source) setValue(key, filePath string, data []byte, fileOpt sourceFileOpt) error {
+	if fileOpt.trimTrailingNewline {
+		data = bytes.TrimRight(data, "\r\n")
 	}
-	for _, optSetter := range optSetters {
-		optSetter(opts)
+	var value interface{} = data
+	for _, t := range fileOpt.transforms {
+		var err error
+		if value, err = t(value); err != nil {
+			return fmt.Errorf("failed to transform %s: %w", key, err)
+		}
+	}
+	s.valuesByKey[key] = val.Raw{
+		Key: key,
+		Val: value,
+		Origin: val.Origin{
+			Source: s.name,
+			File:   filePath,
+		},
+	}
+	return nil
+}
+
+func (s *source) loadDir(dirPath, keyPrefix string, dirOpt sourceFileOpt) error {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return err
+	}
+	s.filePaths = append(s.filePaths, dirPath)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "..") {
+			continue
+		}
+		entryPath := filepath.Join(dirPath, entry.Name())
+		key := path.Join(keyPrefix, entry.Name())
+
+		// Stat to follow symlinks
+		info, err := os.Stat(entryPath)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if !dirOpt.recursive {
+				continue
+			}
+			if err := s.loadDir(entryPath, key, dirOpt); err != nil {
+				return err
+			}
+			continue
+		}
+		data, err := os.ReadFile(entryPath)
+		if err != nil {
+			return err
+		}
+		s.filePaths = append(s.filePaths, entryPath)
+		if err := s.setValue(key, entryPath, data, dirOpt); err != nil {
+			return err
+		}
 	}
+	return nil
+}
+
+func (s *source) loadDirs(dirs []sourceFileOpt) error {
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir.filePath); dir.ignoreMissing && os.IsNotExist(err) {
+			// Still watched to notice when it's created
+			s.filePaths = append(s.filePaths, dir.filePath)
+			continue
+		}
+		if err := s.loadDir(dir.filePath, "", dir); err != nil {
+			return fmt.Errorf("failed to read dir %s: %w", dir.filePath, err)
+		}
+	}
+	return nil
+}
+
+func load(optSetters ...SourceOpt) (config.Source, error) {
+	opts := newSourceOpts(optSetters)
 	src := &source{
-		valuesByKey: make(map[string]val.Raw),
+		name:         opts.name,
+		pollInterval: opts.pollInterval,
+		valuesByKey:  make(map[string]val.Raw),
+	}
+	if err := src.loadDirs(opts.dirs); err != nil {
+		return nil, err
 	}
 	for key, env := range opts.keyToSourceFile {
+		src.filePaths = append(src.filePaths, env.filePath)
 		data, err := os.ReadFile(env.filePath)
 		isMissing := os.IsNotExist(err)
 		if err != nil && !(env.ignoreMissing && isMissing) {
-			return nil, err
+			return nil, fmt.Errorf("failed to read %s: %w", key, err)
 		}
 		if isMissing {
 			continue
 		}
-		src.valuesByKey[key] = val.Raw{
-			Key: key,
-			Val: data,
+		if err := src.setValue(key, env.filePath, data, env); err != nil {
+			return nil, err
 		}
 	}
 	return src, nil
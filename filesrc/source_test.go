@@ -1,13 +1,17 @@
 package filesrc
 
 import (
+	"context"
+	"encoding/base64"
 	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/brianvoe/gofakeit/v6"
 	"github.com/gocombo/config"
+	"github.com/gocombo/config/val"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -68,6 +72,22 @@ func Test_FileSource(t *testing.T) {
 		assertVal(t, source, path1, val1)
 		assertVal(t, source, path2, val2)
 	})
+	t.Run("set value origin", func(t *testing.T) {
+		filePath1 := gofakeit.Generate("test_env_1_{word}")
+		path1 := gofakeit.Generate("test/path-1/{word}")
+		setFileValue(t, tmpDir, filePath1, gofakeit.SentenceSimple())
+		source, err := loadFromOpts(
+			Set(path1).From(filepath.Join(tmpDir, filePath1)),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		got, ok := source.GetValue(path1)
+		if !assert.True(t, ok, "key %s not found", path1) {
+			return
+		}
+		assert.Equal(t, val.Origin{Source: "file", File: filepath.Join(tmpDir, filePath1)}, got.Origin)
+	})
 	t.Run("handle empty values", func(t *testing.T) {
 		filePath1 := gofakeit.Generate("test_env_1_{word}")
 		filePath2 := gofakeit.Generate("test_env_2_{word}")
@@ -86,25 +106,46 @@ func Test_FileSource(t *testing.T) {
 		assertVal(t, source, path1, val1)
 		assertVal(t, source, path2, "")
 	})
+	t.Run("set source name", func(t *testing.T) {
+		filePath1 := gofakeit.Generate("test_env_1_{word}")
+		path1 := gofakeit.Generate("test/path-1/{word}")
+		name := gofakeit.Word()
+		setFileValue(t, tmpDir, filePath1, gofakeit.SentenceSimple())
+		source, err := loadFromOpts(
+			Set(path1).From(filepath.Join(tmpDir, filePath1)),
+			WithName(name),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, name, source.(config.Named).Name())
+		got, ok := source.GetValue(path1)
+		if !assert.True(t, ok, "key %s not found", path1) {
+			return
+		}
+		assert.Equal(t, name, got.Origin.Source)
+	})
 	t.Run("fail if missing", func(t *testing.T) {
 		filePath1 := gofakeit.Generate("test_env_1_{word}")
 		path1 := gofakeit.Generate("test/path-1/{word}")
 		_, err := loadFromOpts(
-			Set(path1).From(filepath.Join(tmpDir, filePath1)),
+			Set(path1).From(filepath.Join(t.TempDir(), filePath1)),
 		)
 		if !assert.Error(t, err) {
 			return
 		}
-		assert.True(t, os.IsNotExist(err))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		assert.Contains(t, err.Error(), "failed to load file: failed to read "+path1)
 	})
 	t.Run("handle ignore missing values", func(t *testing.T) {
 		filePath1 := gofakeit.Generate("test_env_1_{word}")
 		filePath2 := gofakeit.Generate("test_env_2_{word}")
 		path1 := gofakeit.Generate("test/path-1/{word}")
 		path2 := gofakeit.Generate("test/path-2/{word}")
+		missingDir := t.TempDir()
 		source, err := loadFromOpts(
-			Set(path1).From(filepath.Join(tmpDir, filePath1), IgnoreMissing()),
-			Set(path2).From(filepath.Join(tmpDir, filePath2), IgnoreMissing()),
+			Set(path1).From(filepath.Join(missingDir, filePath1), IgnoreMissing()),
+			Set(path2).From(filepath.Join(missingDir, filePath2), IgnoreMissing()),
 		)
 		if !assert.NoError(t, err) {
 			return
@@ -119,4 +160,301 @@ func Test_FileSource(t *testing.T) {
 			return
 		}
 	})
+	t.Run("notify when files change", func(t *testing.T) {
+		filePath1 := gofakeit.Generate("test_env_1_{word}")
+		filePath2 := gofakeit.Generate("test_env_2_{word}")
+		setFileValue(t, tmpDir, filePath1, gofakeit.SentenceSimple())
+		source, err := loadFromOpts(
+			Set(gofakeit.Generate("test/path-1/{word}")).From(filepath.Join(tmpDir, filePath1)),
+			Set(gofakeit.Generate("test/path-2/{word}")).From(filepath.Join(tmpDir, filePath2), IgnoreMissing()),
+			WithPollInterval(time.Millisecond),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		watchable, ok := source.(config.Watchable)
+		if !assert.True(t, ok, "source is not watchable") {
+			return
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		notified := make(chan struct{}, 1)
+		go watchable.Watch(ctx, func() {
+			select {
+			case notified <- struct{}{}:
+			default:
+			}
+		})
+		time.Sleep(20 * time.Millisecond)
+		setFileValue(t, tmpDir, filePath2, gofakeit.SentenceSimple())
+		select {
+		case <-notified:
+		case <-time.After(time.Second):
+			assert.Fail(t, "change not notified")
+		}
+	})
+	t.Run("Dir", func(t *testing.T) {
+		mkdir := func(t *testing.T, dirPath string) {
+			if err := os.MkdirAll(dirPath, 0o755); !assert.NoError(t, err) {
+				t.FailNow()
+			}
+		}
+		symlink := func(t *testing.T, oldname, newname string) {
+			if err := os.Symlink(oldname, newname); !assert.NoError(t, err) {
+				t.FailNow()
+			}
+		}
+		t.Run("read every file in the directory", func(t *testing.T) {
+			dir := t.TempDir()
+			mkdir(t, filepath.Join(dir, "nested"))
+			val1 := gofakeit.SentenceSimple()
+			val2 := gofakeit.SentenceSimple()
+			setFileValue(t, dir, "key1", val1)
+			setFileValue(t, dir, "key2", val2)
+			setFileValue(t, filepath.Join(dir, "nested"), "key3", gofakeit.SentenceSimple())
+			source, err := loadFromOpts(Dir(dir))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assertVal(t, source, "key1", val1)
+			assertVal(t, source, "key2", val2)
+			_, ok := source.GetValue("nested/key3")
+			assert.False(t, ok, "nested value found in non recursive mode")
+			got, ok := source.GetValue("key1")
+			if assert.True(t, ok) {
+				assert.Equal(t, val.Origin{Source: "file", File: filepath.Join(dir, "key1")}, got.Origin)
+			}
+		})
+		t.Run("read subdirectories recursively", func(t *testing.T) {
+			dir := t.TempDir()
+			mkdir(t, filepath.Join(dir, "db", "primary"))
+			val1 := gofakeit.SentenceSimple()
+			val2 := gofakeit.SentenceSimple()
+			setFileValue(t, filepath.Join(dir, "db"), "user", val1)
+			setFileValue(t, filepath.Join(dir, "db", "primary"), "password", val2)
+			source, err := loadFromOpts(Dir(dir, Recursive()))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assertVal(t, source, "db/user", val1)
+			assertVal(t, source, "db/primary/password", val2)
+		})
+		t.Run("skip kubernetes volume internals", func(t *testing.T) {
+			dir := t.TempDir()
+			dataDir := "..2024_01_01_00_00_00.000000001"
+			mkdir(t, filepath.Join(dir, dataDir))
+			val1 := gofakeit.SentenceSimple()
+			setFileValue(t, filepath.Join(dir, dataDir), "key1", val1)
+			symlink(t, dataDir, filepath.Join(dir, "..data"))
+			symlink(t, filepath.Join("..data", "key1"), filepath.Join(dir, "key1"))
+			source, err := loadFromOpts(Dir(dir, Recursive()))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assertVal(t, source, "key1", val1)
+			_, ok := source.GetValue("..data/key1")
+			assert.False(t, ok, "kubernetes internal value found")
+		})
+		t.Run("trim trailing newline", func(t *testing.T) {
+			dir := t.TempDir()
+			val1 := gofakeit.SentenceSimple()
+			setFileValue(t, dir, "key1", val1+"\n")
+			setFileValue(t, dir, "key2", val1+"\r\n")
+			source, err := loadFromOpts(Dir(dir, TrimTrailingNewline()))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assertVal(t, source, "key1", val1)
+			assertVal(t, source, "key2", val1)
+		})
+		t.Run("keep binary content", func(t *testing.T) {
+			dir := t.TempDir()
+			want := []byte{0x00, 0xff, 0x10, '\n'}
+			if !assert.NoError(t, os.WriteFile(filepath.Join(dir, "key1"), want, 0o644)) {
+				return
+			}
+			source, err := loadFromOpts(Dir(dir))
+			if !assert.NoError(t, err) {
+				return
+			}
+			got, ok := source.GetValue("key1")
+			if assert.True(t, ok) {
+				assert.Equal(t, want, got.Val)
+			}
+		})
+		t.Run("prefer values set explicitly", func(t *testing.T) {
+			dir := t.TempDir()
+			val1 := gofakeit.SentenceSimple()
+			setFileValue(t, dir, "key1", gofakeit.SentenceSimple())
+			explicitDir := t.TempDir()
+			setFileValue(t, explicitDir, "key1", val1)
+			source, err := loadFromOpts(
+				Set("key1").From(filepath.Join(explicitDir, "key1")),
+				Dir(dir),
+			)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assertVal(t, source, "key1", val1)
+		})
+		t.Run("fail if missing", func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), gofakeit.Generate("dir-{word}"))
+			_, err := loadFromOpts(Dir(dir))
+			assert.ErrorIs(t, err, os.ErrNotExist)
+			assert.Contains(t, err.Error(), "failed to load file: failed to read dir "+dir)
+		})
+		t.Run("ignore missing", func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), gofakeit.Generate("dir-{word}"))
+			source, err := loadFromOpts(Dir(dir, IgnoreMissing()))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.NotNil(t, source)
+		})
+		t.Run("notify when file added", func(t *testing.T) {
+			dir := t.TempDir()
+			setFileValue(t, dir, "key1", gofakeit.SentenceSimple())
+			source, err := loadFromOpts(Dir(dir), WithPollInterval(time.Millisecond))
+			if !assert.NoError(t, err) {
+				return
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			notified := make(chan struct{}, 1)
+			go source.(config.Watchable).Watch(ctx, func() {
+				select {
+				case notified <- struct{}{}:
+				default:
+				}
+			})
+			time.Sleep(20 * time.Millisecond)
+			setFileValue(t, dir, "key2", gofakeit.SentenceSimple())
+			select {
+			case <-notified:
+			case <-time.After(time.Second):
+				assert.Fail(t, "change not notified")
+			}
+		})
+	})
+	t.Run("transforms", func(t *testing.T) {
+		loadTransformed := func(t *testing.T, content string, pathOpts ...FromOpt) (interface{}, error) {
+			filePath := filepath.Join(t.TempDir(), gofakeit.Generate("test_env_{word}"))
+			setFileValue(t, "", filePath, content)
+			source, err := loadFromOpts(Set("key").From(filePath, pathOpts...))
+			if err != nil {
+				return nil, err
+			}
+			got, ok := source.GetValue("key")
+			if !assert.True(t, ok, "key not found") {
+				t.FailNow()
+			}
+			return got.Val, nil
+		}
+		t.Run("trim space", func(t *testing.T) {
+			want := gofakeit.SentenceSimple()
+			got, err := loadTransformed(t, " \t"+want+"\n\n", TrimSpace())
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, []byte(want), got)
+		})
+		t.Run("as string", func(t *testing.T) {
+			want := gofakeit.SentenceSimple()
+			got, err := loadTransformed(t, want+"\n", TrimSpace(), AsString())
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, want, got)
+		})
+		t.Run("trim space of string", func(t *testing.T) {
+			want := gofakeit.SentenceSimple()
+			got, err := loadTransformed(t, " "+want+"\n", AsString(), TrimSpace())
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, want, got)
+		})
+		t.Run("base64 decode", func(t *testing.T) {
+			want := []byte{0x00, 0xff, 0x10}
+			got, err := loadTransformed(t, base64.StdEncoding.EncodeToString(want)+"\n", Base64Decode())
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, want, got)
+		})
+		t.Run("fail to decode invalid base64", func(t *testing.T) {
+			_, err := loadTransformed(t, "not base64!", Base64Decode())
+			assert.ErrorContains(t, err, "failed to transform key: failed to decode base64: ")
+		})
+		t.Run("parse JSON", func(t *testing.T) {
+			got, err := loadTransformed(t, `{"host": "localhost", "ports": [80, 443]}`, ParseJSON())
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, map[string]interface{}{
+				"host":  "localhost",
+				"ports": []interface{}{80.0, 443.0},
+			}, got)
+		})
+		t.Run("fail to parse invalid JSON", func(t *testing.T) {
+			_, err := loadTransformed(t, "not json", ParseJSON())
+			assert.ErrorContains(t, err, "failed to transform key: failed to parse JSON: ")
+		})
+		t.Run("parse JSON of string", func(t *testing.T) {
+			got, err := loadTransformed(t, `[80, 443]`, AsString(), ParseJSON())
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, []interface{}{80.0, 443.0}, got)
+		})
+		t.Run("fail if transform gets unexpected value", func(t *testing.T) {
+			_, err := loadTransformed(t, "[]", ParseJSON(), AsString())
+			assert.ErrorContains(t, err, "failed to transform key: expected []byte or string, got []interface {}")
+			_, err = loadTransformed(t, "[]", ParseJSON(), TrimSpace())
+			assert.ErrorContains(t, err, "failed to transform key: expected []byte or string, got []interface {}")
+		})
+		t.Run("apply to directory files", func(t *testing.T) {
+			dir := t.TempDir()
+			setFileValue(t, dir, "key1", " value1 ")
+			source, err := loadFromOpts(Dir(dir, TrimSpace(), AsString()))
+			if !assert.NoError(t, err) {
+				return
+			}
+			got, ok := source.GetValue("key1")
+			if assert.True(t, ok) {
+				assert.Equal(t, "value1", got.Val)
+			}
+		})
+		t.Run("define typed values", func(t *testing.T) {
+			type dbConfig struct {
+				Host  string `json:"host"`
+				Ports []int  `json:"ports"`
+			}
+			type secretsConfig struct {
+				port int
+				db   dbConfig
+			}
+			secretsDir := t.TempDir()
+			portFile := filepath.Join(secretsDir, gofakeit.Generate("port_{word}"))
+			dbFile := filepath.Join(secretsDir, gofakeit.Generate("db_{word}"))
+			setFileValue(t, "", portFile, "8080\n")
+			setFileValue(t, "", dbFile, base64.StdEncoding.EncodeToString([]byte(`{"host": "db", "ports": [5432]}`)))
+			cfg, err := config.Load(func(p val.Provider) *secretsConfig {
+				return &secretsConfig{
+					port: val.Define[int](p, "port"),
+					db:   val.Define[dbConfig](p, "db"),
+				}
+			}, Load(
+				Set("port").From(portFile, TrimSpace(), AsString()),
+				Set("db").From(dbFile, Base64Decode(), ParseJSON()),
+			))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, &secretsConfig{
+				port: 8080,
+				db:   dbConfig{Host: "db", Ports: []int{5432}},
+			}, cfg)
+		})
+	})
 }
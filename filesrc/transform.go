@@ -0,0 +1,92 @@
+package filesrc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// transform converts the file content, value is
+// []byte initially but previous transforms may change it
+type transform func(value interface{}) (interface{}, error)
+
+func withTransform(t transform) FromOpt {
+	return func(s *sourceFileOpt) {
+		s.transforms = append(s.transforms, t)
+	}
+}
+
+func toBytes(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return nil, fmt.Errorf("expected []byte or string, got %T", value)
+	}
+}
+
+// TrimSpace removes leading and trailing white space from the file content
+func TrimSpace() FromOpt {
+	return withTransform(func(value interface{}) (interface{}, error) {
+		switch v := value.(type) {
+		case string:
+			return strings.TrimSpace(v), nil
+		default:
+			data, err := toBytes(value)
+			if err != nil {
+				return nil, err
+			}
+			return bytes.TrimSpace(data), nil
+		}
+	})
+}
+
+// Base64Decode decodes standard base64 encoded file content.
+// Leading and trailing white space is ignored.
+func Base64Decode() FromOpt {
+	return withTransform(func(value interface{}) (interface{}, error) {
+		data, err := toBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		data = bytes.TrimSpace(data)
+		decoded := make([]byte, base64.StdEncoding.DecodedLen(len(data)))
+		n, err := base64.StdEncoding.Decode(decoded, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode base64: %w", err)
+		}
+		return decoded[:n], nil
+	})
+}
+
+// ParseJSON parses the file content as JSON, so objects and
+// arrays can be used as struct, map and slice values
+func ParseJSON() FromOpt {
+	return withTransform(func(value interface{}) (interface{}, error) {
+		data, err := toBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		var parsed interface{}
+		if err := json.Unmarshal(data, &parsed); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		return parsed, nil
+	})
+}
+
+// AsString makes the value a string instead of []byte, so it can be
+// converted to numbers, booleans, durations and other typed values
+func AsString() FromOpt {
+	return withTransform(func(value interface{}) (interface{}, error) {
+		data, err := toBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	})
+}
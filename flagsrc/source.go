@@ -0,0 +1,184 @@
+package flagsrc
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gocombo/config"
+	"github.com/gocombo/config/val"
+)
+
+// Overrides is a flag.Value that collects repeated key=value pairs,
+// e.g --set server/port=9090 --set db/host=localhost
+type Overrides struct {
+	values map[string]string
+}
+
+func (o *Overrides) String() string {
+	if o == nil || len(o.values) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(o.values))
+	for key, value := range o.values {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Set parses a single key=value pair. Later pairs for the same key win.
+func (o *Overrides) Set(pair string) error {
+	key, value, ok := strings.Cut(pair, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", pair)
+	}
+	if o.values == nil {
+		o.values = map[string]string{}
+	}
+	o.values[key] = value
+	return nil
+}
+
+// RegisterOverrides defines a repeatable flag with the given name that sets
+// arbitrary config values, e.g --set server/port=9090.
+// Load picks overrides from the flag set automatically.
+func RegisterOverrides(fs *flag.FlagSet, name string) *Overrides {
+	overrides := &Overrides{}
+	fs.Var(overrides, name, "set config value using key=value, can be repeated")
+	return overrides
+}
+
+type sourceOpts struct {
+	name          string
+	flagNameToKey map[string]string
+}
+
+type SourceOpt func(opts *sourceOpts)
+
+// WithName sets a name of the source that is used in errors
+// and value origin. Default name is "flags".
+func WithName(name string) SourceOpt {
+	return func(opts *sourceOpts) {
+		opts.name = name
+	}
+}
+
+type LoadValOptBuilder struct {
+	valuePath string
+}
+
+// From defines a flag to set value from
+func (b *LoadValOptBuilder) From(flagName string) SourceOpt {
+	return func(opts *sourceOpts) {
+		opts.flagNameToKey[flagName] = b.valuePath
+	}
+}
+
+// Set config value using From. Flags that are not
+// mapped explicitly are available using the flag name as a key.
+func Set(path string) *LoadValOptBuilder {
+	return &LoadValOptBuilder{
+		valuePath: path,
+	}
+}
+
+type source struct {
+	name        string
+	valuesByKey map[string]val.Raw
+}
+
+func (s *source) Name() string {
+	return s.name
+}
+
+func (s *source) GetValue(key string) (val.Raw, bool) {
+	rawVal, ok := s.valuesByKey[key]
+	if !ok {
+		return val.Raw{}, false
+	}
+	return rawVal, true
+}
+
+func newSourceOpts(optSetters []SourceOpt) *sourceOpts {
+	opts := &sourceOpts{
+		name:          "flags",
+		flagNameToKey: map[string]string{},
+	}
+	for _, optSetter := range optSetters {
+		optSetter(opts)
+	}
+	return opts
+}
+
+// Load reads values of the flags that were explicitly set when the flag set
+// was parsed, flags left with their defaults are not present in the source.
+// Values set with Overrides take precedence over other flags.
+// Add it last to make command line take precedence over other sources.
+func Load(fs *flag.FlagSet, optSetters ...SourceOpt) config.LoadOpt {
+	return func(opts config.LoadOpts) {
+		name := newSourceOpts(optSetters).name
+		opts.AddSourceLoader(config.NamedSourceLoader(name, func() (config.Source, error) {
+			return load(fs, optSetters...), nil
+		}))
+	}
+}
+
+func flagValue(value flag.Value) interface{} {
+	getter, ok := value.(flag.Getter)
+	if !ok {
+		return value.String()
+	}
+	switch v := getter.Get().(type) {
+	case uint, uint64:
+		// There are no unsigned converters, string is parsed instead
+		return value.String()
+	default:
+		return v
+	}
+}
+
+func load(fs *flag.FlagSet, optSetters ...SourceOpt) config.Source {
+	opts := newSourceOpts(optSetters)
+	src := &source{
+		name:        opts.name,
+		valuesByKey: make(map[string]val.Raw),
+	}
+	type overrideFlag struct {
+		name      string
+		overrides *Overrides
+	}
+	var overrideFlags []overrideFlag
+	fs.Visit(func(f *flag.Flag) {
+		if overrides, ok := f.Value.(*Overrides); ok {
+			overrideFlags = append(overrideFlags, overrideFlag{name: f.Name, overrides: overrides})
+			return
+		}
+		key, ok := opts.flagNameToKey[f.Name]
+		if !ok {
+			key = f.Name
+		}
+		src.valuesByKey[key] = val.Raw{
+			Key: key,
+			Val: flagValue(f.Value),
+			Origin: val.Origin{
+				Source: src.name,
+				Flag:   f.Name,
+			},
+		}
+	})
+	for _, f := range overrideFlags {
+		for key, value := range f.overrides.values {
+			src.valuesByKey[key] = val.Raw{
+				Key: key,
+				Val: value,
+				Origin: val.Origin{
+					Source: src.name,
+					Flag:   f.name,
+				},
+			}
+		}
+	}
+	return src
+}
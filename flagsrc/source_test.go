@@ -0,0 +1,164 @@
+package flagsrc
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/gocombo/config"
+	"github.com/gocombo/config/val"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockLoadOpts struct {
+	sourceLoaders []config.SourceLoader
+}
+
+func (m *mockLoadOpts) AddSourceLoader(loader config.SourceLoader) {
+	m.sourceLoaders = append(m.sourceLoaders, loader)
+}
+
+func Test_FlagSource(t *testing.T) {
+	loadFromOpts := func(fs *flag.FlagSet, optsSetters ...SourceOpt) (config.Source, error) {
+		mockOpts := &mockLoadOpts{}
+		loadOpt := Load(fs, optsSetters...)
+		loadOpt(mockOpts)
+		if len(mockOpts.sourceLoaders) < 1 {
+			return nil, fmt.Errorf("no source loader added to opts")
+		}
+		return mockOpts.sourceLoaders[0]()
+	}
+	assertVal := func(
+		t *testing.T,
+		source config.Source,
+		key string,
+		wantVal interface{},
+	) {
+		got, ok := source.GetValue(key)
+		if !assert.True(t, ok, "key %s not found", key) {
+			return
+		}
+		assert.Equal(t, wantVal, got.Val)
+	}
+	t.Run("read explicitly set flags", func(t *testing.T) {
+		fs := flag.NewFlagSet(gofakeit.Word(), flag.ContinueOnError)
+		fs.String("str", "", "")
+		fs.Int("int", 0, "")
+		fs.Bool("bool", false, "")
+		fs.Duration("duration", 0, "")
+		fs.String("not-set", gofakeit.Word(), "")
+		wantStr := gofakeit.Word()
+		wantInt := gofakeit.Number(1, 1000)
+		if !assert.NoError(t, fs.Parse([]string{
+			"-str", wantStr,
+			"-int", fmt.Sprint(wantInt),
+			"-bool",
+			"-duration", "1m",
+		})) {
+			return
+		}
+		source, err := loadFromOpts(fs)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assertVal(t, source, "str", wantStr)
+		assertVal(t, source, "int", wantInt)
+		assertVal(t, source, "bool", true)
+		assertVal(t, source, "duration", time.Minute)
+		_, ok := source.GetValue("not-set")
+		assert.False(t, ok, "not set flag is present")
+	})
+	t.Run("read unsigned flags as strings", func(t *testing.T) {
+		fs := flag.NewFlagSet(gofakeit.Word(), flag.ContinueOnError)
+		fs.Uint("uint", 0, "")
+		if !assert.NoError(t, fs.Parse([]string{"-uint", "10"})) {
+			return
+		}
+		source, err := loadFromOpts(fs)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assertVal(t, source, "uint", "10")
+	})
+	t.Run("map flags to keys", func(t *testing.T) {
+		fs := flag.NewFlagSet(gofakeit.Word(), flag.ContinueOnError)
+		fs.String("port", "", "")
+		wantPort := fmt.Sprint(gofakeit.Number(1000, 9999))
+		if !assert.NoError(t, fs.Parse([]string{"-port", wantPort})) {
+			return
+		}
+		name := gofakeit.Word()
+		source, err := loadFromOpts(fs, Set("server/port").From("port"), WithName(name))
+		if !assert.NoError(t, err) {
+			return
+		}
+		got, ok := source.GetValue("server/port")
+		if !assert.True(t, ok, "key server/port not found") {
+			return
+		}
+		assert.Equal(t, val.Raw{
+			Key:    "server/port",
+			Val:    wantPort,
+			Origin: val.Origin{Source: name, Flag: "port"},
+		}, got)
+		_, ok = source.GetValue("port")
+		assert.False(t, ok, "mapped flag is present by its name")
+	})
+	t.Run("read overrides", func(t *testing.T) {
+		fs := flag.NewFlagSet(gofakeit.Word(), flag.ContinueOnError)
+		fs.String("server/port", "", "")
+		overrides := RegisterOverrides(fs, "set")
+		wantPort := fmt.Sprint(gofakeit.Number(1000, 9999))
+		wantHost := gofakeit.DomainName()
+		if !assert.NoError(t, fs.Parse([]string{
+			"-set", "server/port=" + wantPort,
+			"-server/port", "1",
+			"--set", "db/host=" + gofakeit.DomainName(),
+			"--set", "db/host=" + wantHost,
+			"--set", "db/empty=",
+		})) {
+			return
+		}
+		source, err := loadFromOpts(fs)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assertVal(t, source, "server/port", wantPort)
+		assertVal(t, source, "db/host", wantHost)
+		assertVal(t, source, "db/empty", "")
+		got, ok := source.GetValue("db/host")
+		if assert.True(t, ok) {
+			assert.Equal(t, val.Origin{Source: "flags", Flag: "set"}, got.Origin)
+		}
+		_, ok = source.GetValue("set")
+		assert.False(t, ok, "overrides flag is present by its name")
+		assert.Equal(t, "db/empty=,db/host="+wantHost+",server/port="+wantPort, overrides.String())
+	})
+	t.Run("set source name", func(t *testing.T) {
+		fs := flag.NewFlagSet(gofakeit.Word(), flag.ContinueOnError)
+		fs.String("str", "", "")
+		if !assert.NoError(t, fs.Parse([]string{"-str", gofakeit.Word()})) {
+			return
+		}
+		name := gofakeit.Word()
+		source, err := loadFromOpts(fs, WithName(name))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, name, source.(config.Named).Name())
+		got, ok := source.GetValue("str")
+		if assert.True(t, ok) {
+			assert.Equal(t, name, got.Origin.Source)
+		}
+	})
+	t.Run("fail parsing invalid overrides", func(t *testing.T) {
+		fs := flag.NewFlagSet(gofakeit.Word(), flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		RegisterOverrides(fs, "set")
+		err := fs.Parse([]string{"-set", "no-value"})
+		assert.ErrorContains(t, err, `expected key=value, got "no-value"`)
+	})
+}
@@ -3,12 +3,13 @@ module github.com/gocombo/config
 go 1.20
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/brianvoe/gofakeit/v6 v6.21.0
 	github.com/stretchr/testify v1.8.2
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,255 @@
+package httpsrc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gocombo/config"
+	"github.com/gocombo/config/internal/keypath"
+	"github.com/gocombo/config/val"
+)
+
+// DefaultPollInterval is used to check the document
+// for changes if poll interval is not configured
+const DefaultPollInterval = 30 * time.Second
+
+// DefaultTimeout is a timeout of a single request
+const DefaultTimeout = 10 * time.Second
+
+type loadOpts struct {
+	name         string
+	header       http.Header
+	client       *http.Client
+	timeout      time.Duration
+	pollInterval time.Duration
+}
+
+func defaultLoadOpts() loadOpts {
+	return loadOpts{
+		header:       http.Header{},
+		client:       http.DefaultClient,
+		timeout:      DefaultTimeout,
+		pollInterval: DefaultPollInterval,
+	}
+}
+
+func (o *loadOpts) set(optSetter []LoadOpt) {
+	for _, opt := range optSetter {
+		opt(o)
+	}
+}
+
+func (o *loadOpts) sourceName(rawURL string) string {
+	if o.name != "" {
+		return o.name
+	}
+	return redactURL(rawURL)
+}
+
+// redactURL keeps only scheme, host and path of the url so
+// credentials and tokens don't end up in errors and value origins
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "invalid url"
+	}
+	return (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String()
+}
+
+type LoadOpt func(opts *loadOpts)
+
+// WithName sets a name of the source that is used in errors
+// and value origin. URL of the document without userinfo, query
+// and fragment is used by default.
+func WithName(name string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.name = name
+	}
+}
+
+// WithHeader adds a header that is sent with every request
+func WithHeader(key, value string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.header.Add(key, value)
+	}
+}
+
+// WithBearerToken authenticates requests with the token
+func WithBearerToken(token string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// WithBasicAuth authenticates requests with the username and password
+func WithBasicAuth(username, password string) LoadOpt {
+	return func(opts *loadOpts) {
+		credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+		opts.header.Set("Authorization", "Basic "+credentials)
+	}
+}
+
+// WithClient sets a client that is used to send requests.
+// http.DefaultClient is used by default.
+func WithClient(client *http.Client) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.client = client
+	}
+}
+
+// WithTimeout sets a timeout of a single request. Default is DefaultTimeout,
+// non-positive values are ignored.
+func WithTimeout(timeout time.Duration) LoadOpt {
+	return func(opts *loadOpts) {
+		if timeout > 0 {
+			opts.timeout = timeout
+		}
+	}
+}
+
+// WithPollInterval defines how often the document is checked for changes
+// when config is watched. Default is DefaultPollInterval, non-positive
+// values are ignored.
+func WithPollInterval(interval time.Duration) LoadOpt {
+	return func(opts *loadOpts) {
+		if interval > 0 {
+			opts.pollInterval = interval
+		}
+	}
+}
+
+type document struct {
+	etag string
+
+	// hash is used to detect changes if server does not support ETag
+	hash [sha256.Size]byte
+}
+
+type source struct {
+	name      string
+	url       string
+	opts      loadOpts
+	doc       document
+	rawValues map[string]interface{}
+}
+
+func (src *source) Name() string {
+	return src.name
+}
+
+// fetch gets the document. If etag is not empty it's sent in If-None-Match
+// header and notModified is returned if the document has the same ETag.
+func (src *source) fetch(ctx context.Context, etag string) (data []byte, doc document, notModified bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, src.opts.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src.url, nil)
+	if err != nil {
+		return nil, document{}, false, err
+	}
+	req.Header = src.opts.header.Clone()
+	req.Header.Set("Accept", "application/json")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	res, err := src.opts.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = redactURL(src.url)
+		}
+		return nil, document{}, false, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotModified && etag != "" {
+		return nil, document{}, true, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, document{}, false, fmt.Errorf("unexpected status: %s", res.Status)
+	}
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		return nil, document{}, false, fmt.Errorf("failed to read response: %w", err)
+	}
+	return data, document{
+		etag: res.Header.Get("ETag"),
+		hash: sha256.Sum256(data),
+	}, false, nil
+}
+
+// Watch polls the document for changes. If-None-Match is used if
+// server responds with ETag, otherwise content of the document is compared.
+// Failed requests are ignored and retried on the next poll.
+func (src *source) Watch(ctx context.Context, notify func()) {
+	ticker := time.NewTicker(src.opts.pollInterval)
+	defer ticker.Stop()
+	current := src.doc
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, doc, notModified, err := src.fetch(ctx, current.etag)
+			if err != nil || notModified {
+				continue
+			}
+			if doc.etag != current.etag || doc.hash != current.hash {
+				current = doc
+				notify()
+			}
+		}
+	}
+}
+
+func (src *source) GetValue(key string) (val.Raw, bool) {
+	if v := keypath.Lookup(key, src.rawValues); v != nil {
+		return val.Raw{
+			Key: key,
+			Val: v,
+			Origin: val.Origin{
+				Source:  src.name,
+				Pointer: keypath.JSONPointer(key),
+			},
+		}, true
+	}
+	return val.Raw{}, false
+}
+
+// Load fetches JSON document from the url. Nested values are available
+// using "/" separated keys same way as in jsonsrc.
+func Load(rawURL string, optSetter ...LoadOpt) config.LoadOpt {
+	return func(opts config.LoadOpts) {
+		srcOpts := defaultLoadOpts()
+		srcOpts.set(optSetter)
+		opts.AddSourceLoader(config.NamedSourceLoader(srcOpts.sourceName(rawURL), func() (config.Source, error) {
+			return load(rawURL, optSetter...)
+		}))
+	}
+}
+
+func load(rawURL string, optSetter ...LoadOpt) (config.Source, error) {
+	opts := defaultLoadOpts()
+	opts.set(optSetter)
+	src := &source{
+		name:      opts.sourceName(rawURL),
+		url:       rawURL,
+		opts:      opts,
+		rawValues: map[string]interface{}{},
+	}
+	data, doc, _, err := src.fetch(context.Background(), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch document: %w", err)
+	}
+	if err := json.Unmarshal(data, &src.rawValues); err != nil {
+		return nil, fmt.Errorf("failed to decode json: %w", err)
+	}
+	src.doc = doc
+	return src, nil
+}
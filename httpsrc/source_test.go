@@ -0,0 +1,315 @@
+package httpsrc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/gocombo/config"
+	"github.com/gocombo/config/val"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockLoadOpts struct {
+	sourceLoaders []config.SourceLoader
+}
+
+func (m *mockLoadOpts) AddSourceLoader(loader config.SourceLoader) {
+	m.sourceLoaders = append(m.sourceLoaders, loader)
+}
+
+type mockServer struct {
+	mu       sync.Mutex
+	document map[string]interface{}
+	etag     string
+	status   int
+	requests []*http.Request
+}
+
+func (m *mockServer) set(document map[string]interface{}, etag string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.document = document
+	m.etag = etag
+}
+
+func (m *mockServer) lastRequest() *http.Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.requests[len(m.requests)-1]
+}
+
+func (m *mockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, r)
+	if m.status != 0 {
+		w.WriteHeader(m.status)
+		return
+	}
+	if m.etag != "" {
+		if r.Header.Get("If-None-Match") == m.etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", m.etag)
+	}
+	json.NewEncoder(w).Encode(m.document)
+}
+
+func randomDocument() map[string]interface{} {
+	return map[string]interface{}{
+		"str_val_1": gofakeit.Word(),
+		"nested": map[string]interface{}{
+			"str_val_1": gofakeit.Word(),
+		},
+	}
+}
+
+func TestHttpSource(t *testing.T) {
+	startServer := func(t *testing.T) (*mockServer, *httptest.Server) {
+		mock := &mockServer{}
+		srv := httptest.NewServer(mock)
+		t.Cleanup(srv.Close)
+		return mock, srv
+	}
+
+	t.Run("load", func(t *testing.T) {
+		loadFromOpts := func(url string, opts ...LoadOpt) (config.Source, error) {
+			mockOpts := &mockLoadOpts{}
+			loadOpt := Load(url, opts...)
+			loadOpt(mockOpts)
+			if len(mockOpts.sourceLoaders) < 1 {
+				return nil, fmt.Errorf("no source loader added to opts")
+			}
+			return mockOpts.sourceLoaders[0]()
+		}
+		t.Run("read values from the document", func(t *testing.T) {
+			mock, srv := startServer(t)
+			doc := randomDocument()
+			mock.set(doc, "")
+			source, err := loadFromOpts(srv.URL)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, srv.URL, source.(config.Named).Name())
+			got, ok := source.GetValue("str_val_1")
+			if assert.True(t, ok, "Value str_val_1 not found") {
+				assert.Equal(t, doc["str_val_1"], got.Val)
+			}
+			got, ok = source.GetValue("nested/str_val_1")
+			if assert.True(t, ok, "Value nested/str_val_1 not found") {
+				assert.Equal(t, val.Raw{
+					Key: "nested/str_val_1",
+					Val: doc["nested"].(map[string]interface{})["str_val_1"],
+					Origin: val.Origin{
+						Source:  srv.URL,
+						Pointer: "/nested/str_val_1",
+					},
+				}, got)
+			}
+			_, ok = source.GetValue("not/existing/key")
+			assert.False(t, ok, "Value not/existing/key found")
+		})
+		t.Run("send headers", func(t *testing.T) {
+			mock, srv := startServer(t)
+			mock.set(randomDocument(), "")
+			headerVal := gofakeit.Word()
+			_, err := loadFromOpts(srv.URL, WithHeader("X-Test", headerVal))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, headerVal, mock.lastRequest().Header.Get("X-Test"))
+			assert.Equal(t, "application/json", mock.lastRequest().Header.Get("Accept"))
+		})
+		t.Run("send bearer token", func(t *testing.T) {
+			mock, srv := startServer(t)
+			mock.set(randomDocument(), "")
+			token := gofakeit.UUID()
+			_, err := loadFromOpts(srv.URL, WithBearerToken(token))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, "Bearer "+token, mock.lastRequest().Header.Get("Authorization"))
+		})
+		t.Run("send basic auth", func(t *testing.T) {
+			mock, srv := startServer(t)
+			mock.set(randomDocument(), "")
+			wantUser := gofakeit.Username()
+			wantPassword := gofakeit.Password(true, true, true, false, false, 10)
+			_, err := loadFromOpts(srv.URL, WithBasicAuth(wantUser, wantPassword))
+			if !assert.NoError(t, err) {
+				return
+			}
+			user, password, ok := mock.lastRequest().BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, wantUser, user)
+			assert.Equal(t, wantPassword, password)
+		})
+		t.Run("fail on unexpected status", func(t *testing.T) {
+			mock, srv := startServer(t)
+			mock.status = http.StatusForbidden
+			name := gofakeit.Word()
+			_, err := loadFromOpts(srv.URL, WithName(name))
+			assert.EqualError(t, err, fmt.Sprintf(
+				"failed to load %s: failed to fetch document: unexpected status: 403 Forbidden", name,
+			))
+		})
+		t.Run("redact url in default name", func(t *testing.T) {
+			mock, srv := startServer(t)
+			mock.set(randomDocument(), "")
+			u, err := url.Parse(srv.URL)
+			if !assert.NoError(t, err) {
+				return
+			}
+			u.User = url.UserPassword(gofakeit.Username(), gofakeit.Password(true, true, true, false, false, 10))
+			u.Path = "/" + gofakeit.Word()
+			u.RawQuery = "token=" + gofakeit.UUID()
+			u.Fragment = gofakeit.Word()
+			source, err := loadFromOpts(u.String())
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, srv.URL+u.Path, source.(config.Named).Name())
+		})
+		t.Run("redact url in request errors", func(t *testing.T) {
+			srv := httptest.NewServer(http.NotFoundHandler())
+			srv.Close()
+			token := gofakeit.UUID()
+			_, err := loadFromOpts(srv.URL + "/doc?token=" + token)
+			urlErr := &url.Error{}
+			if assert.ErrorAs(t, err, &urlErr) {
+				assert.Equal(t, srv.URL+"/doc", urlErr.URL)
+			}
+			assert.NotContains(t, err.Error(), token)
+		})
+		t.Run("fail if not a JSON", func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("not a json"))
+			}))
+			defer srv.Close()
+			_, err := loadFromOpts(srv.URL)
+			jsonErr := &json.SyntaxError{}
+			assert.ErrorAs(t, err, &jsonErr)
+		})
+		t.Run("fail on timeout", func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+			}))
+			defer srv.Close()
+			_, err := loadFromOpts(srv.URL, WithTimeout(10*time.Millisecond))
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		})
+		t.Run("use default timeout if not positive", func(t *testing.T) {
+			mock, srv := startServer(t)
+			mock.set(randomDocument(), "")
+			_, err := loadFromOpts(srv.URL, WithTimeout(0))
+			assert.NoError(t, err)
+			_, err = loadFromOpts(srv.URL, WithTimeout(-time.Second))
+			assert.NoError(t, err)
+		})
+		t.Run("use given client", func(t *testing.T) {
+			mock, srv := startServer(t)
+			mock.set(randomDocument(), "")
+			var used bool
+			client := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				used = true
+				return http.DefaultTransport.RoundTrip(r)
+			})}
+			_, err := loadFromOpts(srv.URL, WithClient(client))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.True(t, used, "client not used")
+		})
+	})
+
+	t.Run("Watch", func(t *testing.T) {
+		watch := func(t *testing.T, source config.Source) <-chan struct{} {
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			notified := make(chan struct{}, 1)
+			go source.(config.Watchable).Watch(ctx, func() {
+				select {
+				case notified <- struct{}{}:
+				default:
+				}
+			})
+			return notified
+		}
+		t.Run("use ETag to detect changes", func(t *testing.T) {
+			mock, srv := startServer(t)
+			etag := `"` + gofakeit.UUID() + `"`
+			mock.set(randomDocument(), etag)
+			source, err := load(srv.URL, WithPollInterval(time.Millisecond))
+			if !assert.NoError(t, err) {
+				return
+			}
+			notified := watch(t, source)
+			assert.Eventually(t, func() bool {
+				return mock.lastRequest().Header.Get("If-None-Match") == etag
+			}, time.Second, time.Millisecond)
+			select {
+			case <-notified:
+				assert.Fail(t, "notified without changes")
+				return
+			default:
+			}
+			mock.set(randomDocument(), `"`+gofakeit.UUID()+`"`)
+			select {
+			case <-notified:
+			case <-time.After(time.Second):
+				assert.Fail(t, "change not notified")
+			}
+		})
+		t.Run("use default interval if not positive", func(t *testing.T) {
+			mock, srv := startServer(t)
+			mock.set(randomDocument(), "")
+			source, err := load(srv.URL, WithPollInterval(0))
+			if !assert.NoError(t, err) {
+				return
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			assert.NotPanics(t, func() {
+				source.(config.Watchable).Watch(ctx, func() {})
+			})
+		})
+		t.Run("compare content if no ETag", func(t *testing.T) {
+			mock, srv := startServer(t)
+			doc := randomDocument()
+			mock.set(doc, "")
+			source, err := load(srv.URL, WithPollInterval(time.Millisecond))
+			if !assert.NoError(t, err) {
+				return
+			}
+			notified := watch(t, source)
+			time.Sleep(20 * time.Millisecond)
+			select {
+			case <-notified:
+				assert.Fail(t, "notified without changes")
+				return
+			default:
+			}
+			mock.set(randomDocument(), "")
+			select {
+			case <-notified:
+			case <-time.After(time.Second):
+				assert.Fail(t, "change not notified")
+			}
+		})
+	})
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
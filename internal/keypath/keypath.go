@@ -0,0 +1,33 @@
+// Package keypath implements lookup of "/" separated keys
+// in decoded documents shared between structured sources
+package keypath
+
+import "strings"
+
+// Lookup returns a value of the "/" separated key
+// from the nested source or nil if there is no such value
+func Lookup(key string, source map[string]interface{}) interface{} {
+	if source == nil {
+		return nil
+	}
+	firstSeparatorIndex := strings.Index(key, "/")
+	if firstSeparatorIndex >= 0 {
+		parentKey := key[:firstSeparatorIndex]
+		nestedKey := key[firstSeparatorIndex+1:]
+		if nestedSource, ok := source[parentKey].(map[string]interface{}); ok {
+			return Lookup(
+				nestedKey,
+				nestedSource,
+			)
+		}
+	}
+	if v, ok := source[key]; ok {
+		return v
+	}
+	return nil
+}
+
+// JSONPointer converts the key to a JSON pointer (RFC 6901)
+func JSONPointer(key string) string {
+	return "/" + strings.ReplaceAll(key, "~", "~0")
+}
@@ -0,0 +1,33 @@
+package keypath
+
+import (
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	val1 := gofakeit.Word()
+	val2 := gofakeit.Word()
+	nested := map[string]interface{}{
+		"val2": val2,
+	}
+	source := map[string]interface{}{
+		"val1":   val1,
+		"nested": nested,
+		"a/b":    val1,
+	}
+	assert.Equal(t, val1, Lookup("val1", source))
+	assert.Equal(t, val2, Lookup("nested/val2", source))
+	assert.Equal(t, nested, Lookup("nested", source))
+	assert.Equal(t, val1, Lookup("a/b", source))
+	assert.Nil(t, Lookup("nested/val3", source))
+	assert.Nil(t, Lookup("val1/val2", source))
+	assert.Nil(t, Lookup("val1", nil))
+}
+
+func TestJSONPointer(t *testing.T) {
+	assert.Equal(t, "/server/port", JSONPointer("server/port"))
+	assert.Equal(t, "/a~0b", JSONPointer("a~b"))
+}
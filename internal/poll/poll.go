@@ -0,0 +1,91 @@
+// Package poll implements polling based change detection
+// that is shared between file based sources
+package poll
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"time"
+)
+
+// DefaultInterval is used by sources if poll interval is not configured
+const DefaultInterval = 5 * time.Second
+
+type fileState struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+	hash    [sha256.Size]byte
+}
+
+func readFileState(filePath string, prev fileState) fileState {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return fileState{}
+	}
+	state := fileState{
+		exists:  true,
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		hash:    prev.hash,
+	}
+	if prev.exists && state.modTime.Equal(prev.modTime) && state.size == prev.size {
+		return state
+	}
+	if info.IsDir() {
+		// Directory content is a list of it's entries, so
+		// added and removed files are detected
+		entries, err := os.ReadDir(filePath)
+		if err != nil {
+			return fileState{}
+		}
+		h := sha256.New()
+		for _, entry := range entries {
+			h.Write([]byte(entry.Name() + "\n"))
+		}
+		copy(state.hash[:], h.Sum(nil))
+		return state
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fileState{}
+	}
+	state.hash = sha256.Sum256(data)
+	return state
+}
+
+// Files checks given files every interval and calls notify if any of them
+// was created, removed or had it's content changed. Directories are
+// considered changed when entries are added or removed. Files with updated
+// mtime but same content are not considered changed.
+// Blocks until ctx is done.
+func Files(ctx context.Context, interval time.Duration, notify func(), filePaths ...string) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	states := make([]fileState, len(filePaths))
+	for i, filePath := range filePaths {
+		states[i] = readFileState(filePath, fileState{})
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed := false
+			for i, filePath := range filePaths {
+				state := readFileState(filePath, states[i])
+				if state.exists != states[i].exists || state.hash != states[i].hash {
+					changed = true
+				}
+				states[i] = state
+			}
+			if changed {
+				notify()
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package poll
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	startPolling := func(t *testing.T, filePaths ...string) <-chan struct{} {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		notified := make(chan struct{}, 10)
+		go Files(ctx, time.Millisecond, func() {
+			notified <- struct{}{}
+		}, filePaths...)
+
+		// Let the poller read initial state
+		time.Sleep(20 * time.Millisecond)
+		return notified
+	}
+	writeFile := func(t *testing.T, filePath, data string) {
+		if err := os.WriteFile(filePath, []byte(data), 0o644); !assert.NoError(t, err) {
+			t.FailNow()
+		}
+	}
+	assertNotified := func(t *testing.T, notified <-chan struct{}) {
+		select {
+		case <-notified:
+		case <-time.After(time.Second):
+			assert.Fail(t, "change not notified")
+		}
+	}
+
+	t.Run("notify if content changed", func(t *testing.T) {
+		filePath := filepath.Join(tmpDir, gofakeit.Generate("file-{word}"))
+		writeFile(t, filePath, gofakeit.SentenceSimple())
+		notified := startPolling(t, filePath)
+		writeFile(t, filePath, gofakeit.SentenceSimple()+" changed")
+		assertNotified(t, notified)
+	})
+	t.Run("notify if file added to directory", func(t *testing.T) {
+		dirPath := filepath.Join(tmpDir, gofakeit.Generate("dir-{word}"))
+		if !assert.NoError(t, os.Mkdir(dirPath, 0o755)) {
+			return
+		}
+		notified := startPolling(t, dirPath)
+		writeFile(t, filepath.Join(dirPath, gofakeit.Generate("file-{word}")), gofakeit.SentenceSimple())
+		assertNotified(t, notified)
+	})
+	t.Run("notify if file created", func(t *testing.T) {
+		filePath := filepath.Join(tmpDir, gofakeit.Generate("file-{word}"))
+		notified := startPolling(t, filePath)
+		writeFile(t, filePath, gofakeit.SentenceSimple())
+		assertNotified(t, notified)
+	})
+	t.Run("notify if file removed", func(t *testing.T) {
+		filePath := filepath.Join(tmpDir, gofakeit.Generate("file-{word}"))
+		writeFile(t, filePath, gofakeit.SentenceSimple())
+		notified := startPolling(t, filePath)
+		if !assert.NoError(t, os.Remove(filePath)) {
+			return
+		}
+		assertNotified(t, notified)
+	})
+	t.Run("ignore mtime change with same content", func(t *testing.T) {
+		filePath := filepath.Join(tmpDir, gofakeit.Generate("file-{word}"))
+		data := gofakeit.SentenceSimple()
+		writeFile(t, filePath, data)
+		notified := startPolling(t, filePath)
+		newTime := time.Now().Add(time.Hour)
+		if !assert.NoError(t, os.Chtimes(filePath, newTime, newTime)) {
+			return
+		}
+		writeFile(t, filePath, data)
+		select {
+		case <-notified:
+			assert.Fail(t, "unexpected change notified")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+}
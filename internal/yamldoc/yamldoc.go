@@ -0,0 +1,62 @@
+// Package yamldoc decodes YAML documents into values
+// shared between sources that read YAML payloads
+package yamldoc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"gopkg.in/yaml.v3"
+)
+
+// normalize converts decoded YAML values to types
+// that are produced by encoding/json, so val converters
+// can handle them same way as JSON values
+func normalize(v interface{}) interface{} {
+	switch actualVal := v.(type) {
+	case map[string]interface{}:
+		for key, item := range actualVal {
+			actualVal[key] = normalize(item)
+		}
+		return actualVal
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(actualVal))
+		for key, item := range actualVal {
+			result[fmt.Sprint(key)] = normalize(item)
+		}
+		return result
+	case []interface{}:
+		for i, item := range actualVal {
+			actualVal[i] = normalize(item)
+		}
+		return actualVal
+	case uint64:
+		// Only values that do not fit int are decoded as uint64
+		if actualVal <= math.MaxInt64 {
+			return int64(actualVal)
+		}
+		return float64(actualVal)
+	default:
+		return v
+	}
+}
+
+// Decode reads YAML document with a mapping at the root,
+// so values can be looked up with "/" separated keys.
+// Empty document results in empty map.
+func Decode(r io.Reader) (map[string]interface{}, error) {
+	var document interface{}
+	if err := yaml.NewDecoder(r).Decode(&document); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if document == nil {
+		return map[string]interface{}{}, nil
+	}
+	rawValues, ok := normalize(document).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a mapping, got %T", document)
+	}
+	return rawValues, nil
+}
@@ -0,0 +1,37 @@
+package yamldoc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	t.Run("decode mapping", func(t *testing.T) {
+		val1 := gofakeit.Word()
+		got, err := Decode(strings.NewReader("val1: " + val1 + "\nnested:\n  1: 18446744073709551615\n  2: 10\n"))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, map[string]interface{}{
+			"val1": val1,
+			"nested": map[string]interface{}{
+				"1": float64(18446744073709551615),
+				"2": 10,
+			},
+		}, got)
+	})
+	t.Run("decode empty document", func(t *testing.T) {
+		got, err := Decode(strings.NewReader(""))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, map[string]interface{}{}, got)
+	})
+	t.Run("fail if not a mapping", func(t *testing.T) {
+		_, err := Decode(strings.NewReader("- val1\n"))
+		assert.EqualError(t, err, "expected a mapping, got []interface {}")
+	})
+}
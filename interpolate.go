@@ -0,0 +1,125 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// WithInterpolation makes string values reference other values:
+//   - ${other/key} is replaced with a value of the other key looked up in all sources
+//   - ${env:NAME} is replaced with a value of the environment variable
+//   - ${key:-fallback} and ${env:NAME:-fallback} use the fallback if there is no value
+//   - $${ is replaced with a literal ${
+//
+// Referenced values and strings nested in object and array values are
+// interpolated too, cycles are reported as errors.
+func WithInterpolation() LoadOpt {
+	return withLoadOpts(func(opts *loadOpts) {
+		opts.interpolation = true
+	})
+}
+
+// ErrInterpolationFailed is notified if a value could not be interpolated
+type ErrInterpolationFailed struct {
+	Key string
+	Err error
+}
+
+func (e ErrInterpolationFailed) Error() string {
+	return fmt.Sprintf("failed to interpolate %s: %v", e.Key, e.Err)
+}
+
+func (e ErrInterpolationFailed) Unwrap() error {
+	return e.Err
+}
+
+// interpolationRef returns a value of the key referenced in an interpolated value.
+// Stack holds keys that are being interpolated to detect cycles.
+func (p *valuesProvider) interpolationRef(key string, stack []string) (string, bool, error) {
+	for i, stackKey := range stack {
+		if stackKey == key {
+			cycle := append(append([]string{}, stack[i:]...), key)
+			return "", false, fmt.Errorf("cycle detected: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	raw, ok := p.lookup(key)
+	if !ok {
+		return "", false, nil
+	}
+	switch v := raw.Val.(type) {
+	case string:
+		interpolated, err := p.interpolate(v, append(stack, key))
+		return interpolated, true, err
+	case map[string]interface{}, []interface{}:
+		return "", false, fmt.Errorf("%s is not a scalar value", key)
+	default:
+		return fmt.Sprint(v), true, nil
+	}
+}
+
+// closingBrace returns index of "}" matching "${" that ends at start
+func closingBrace(value string, start int) int {
+	depth := 1
+	for i := start; i < len(value); i++ {
+		switch {
+		case strings.HasPrefix(value[i:], "${"):
+			depth++
+			i++
+		case value[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// interpolateExpr returns a value of name or name:-fallback expression
+func (p *valuesProvider) interpolateExpr(expr string, stack []string) (string, error) {
+	name, fallback, hasFallback := strings.Cut(expr, ":-")
+	var resolved string
+	var found bool
+	var err error
+	if envName, isEnv := strings.CutPrefix(name, "env:"); isEnv {
+		resolved, found = os.LookupEnv(envName)
+	} else {
+		resolved, found, err = p.interpolationRef(name, stack)
+	}
+	if err != nil || found {
+		return resolved, err
+	}
+	if !hasFallback {
+		return "", fmt.Errorf("%s is not set", name)
+	}
+	return p.interpolate(fallback, stack)
+}
+
+func (p *valuesProvider) interpolate(value string, stack []string) (string, error) {
+	if !strings.Contains(value, "${") {
+		return value, nil
+	}
+	var result strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch {
+		case strings.HasPrefix(value[i:], "$${"):
+			result.WriteString("${")
+			i += 2
+		case strings.HasPrefix(value[i:], "${"):
+			end := closingBrace(value, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated ${ at position %d", i)
+			}
+			resolved, err := p.interpolateExpr(value[i+2:end], stack)
+			if err != nil {
+				return "", err
+			}
+			result.WriteString(resolved)
+			i = end
+		default:
+			result.WriteByte(value[i])
+		}
+	}
+	return result.String(), nil
+}
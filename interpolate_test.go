@@ -0,0 +1,223 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/gocombo/config/val"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpolation(t *testing.T) {
+	type config struct {
+		val1 string
+		val2 string
+	}
+	testConfigFactory := func(p val.Provider) *config {
+		return &config{
+			val1: val.Define[string](p, "val1"),
+			val2: val.Define[string](p, "val2", val.Optional()),
+		}
+	}
+	withValues := func(values map[string]interface{}) LoadOpt {
+		src := &mockKeyValueSource{values: map[string]val.Raw{}}
+		for key, v := range values {
+			src.values[key] = val.Raw{Key: key, Val: v}
+		}
+		return func(opts LoadOpts) {
+			opts.AddSourceLoader(func() (Source, error) {
+				return src, nil
+			})
+		}
+	}
+
+	t.Run("interpolate other keys", func(t *testing.T) {
+		host := gofakeit.DomainName()
+		cfg, err := Load(testConfigFactory,
+			withValues(map[string]interface{}{
+				"api/host": host,
+				"api/port": 8080,
+				"api/url":  "https://${api/host}:${api/port}",
+				"val1":     "${api/url}/callback",
+			}),
+			WithInterpolation(),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "https://"+host+":8080/callback", cfg.val1)
+	})
+	t.Run("interpolate across sources", func(t *testing.T) {
+		host := gofakeit.DomainName()
+		cfg, err := Load(testConfigFactory,
+			withValues(map[string]interface{}{
+				"api/host": gofakeit.DomainName(),
+				"val1":     "https://${api/host}",
+			}),
+			withValues(map[string]interface{}{
+				"api/host": host,
+			}),
+			WithInterpolation(),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "https://"+host, cfg.val1)
+	})
+	t.Run("interpolate env", func(t *testing.T) {
+		env := gofakeit.Generate("TEST_ENV_{word}")
+		want := gofakeit.Word()
+		t.Setenv(env, want)
+		cfg, err := Load(testConfigFactory,
+			withValues(map[string]interface{}{"val1": "prefix-${env:" + env + "}"}),
+			WithInterpolation(),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "prefix-"+want, cfg.val1)
+	})
+	t.Run("use fallback", func(t *testing.T) {
+		host := gofakeit.DomainName()
+		cfg, err := Load(testConfigFactory,
+			withValues(map[string]interface{}{
+				"api/host": host,
+				"val1":     "${missing:-${env:TEST_MISSING_ENV:-${api/host}}}",
+				"val2":     "${missing:-}",
+			}),
+			WithInterpolation(),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, &config{val1: host, val2: ""}, cfg)
+	})
+	t.Run("keep escaped", func(t *testing.T) {
+		cfg, err := Load(testConfigFactory,
+			withValues(map[string]interface{}{"val1": "$${api/host} costs $5"}),
+			WithInterpolation(),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "${api/host} costs $5", cfg.val1)
+	})
+	t.Run("interpolate nested values", func(t *testing.T) {
+		type api struct {
+			URL      string   `json:"url"`
+			Port     int      `json:"port"`
+			Callback []string `json:"callbacks"`
+		}
+		host := gofakeit.DomainName()
+		values := map[string]interface{}{
+			"host": host,
+			"api": map[string]interface{}{
+				"url":       "https://${host}",
+				"port":      8080,
+				"callbacks": []interface{}{"https://${host}/callback"},
+			},
+		}
+		got, err := Load(func(p val.Provider) *api {
+			v := val.Define[api](p, "api")
+			return &v
+		},
+			withValues(values),
+			WithInterpolation(),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, &api{
+			URL:      "https://" + host,
+			Port:     8080,
+			Callback: []string{"https://" + host + "/callback"},
+		}, got)
+		assert.Equal(t, "https://${host}", values["api"].(map[string]interface{})["url"], "source value modified")
+	})
+	t.Run("report nested errors", func(t *testing.T) {
+		_, err := Load(func(p val.Provider) *map[string]string {
+			v := val.Define[map[string]string](p, "api")
+			return &v
+		},
+			withValues(map[string]interface{}{
+				"api": map[string]interface{}{"url": "https://${missing}"},
+			}),
+			WithInterpolation(),
+		)
+		assert.EqualError(t, err, "failed building config: failed to interpolate api/url: missing is not set")
+	})
+	t.Run("keep values as is if disabled", func(t *testing.T) {
+		cfg, err := Load(testConfigFactory,
+			withValues(map[string]interface{}{"val1": "${api/host}"}),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "${api/host}", cfg.val1)
+	})
+	t.Run("interpolate before resolving", func(t *testing.T) {
+		env := gofakeit.Generate("TEST_ENV_{word}")
+		want := gofakeit.Word()
+		t.Setenv(env, want)
+		cfg, err := Load(testConfigFactory,
+			withValues(map[string]interface{}{
+				"env/name": env,
+				"val1":     "env://${env/name}",
+			}),
+			WithInterpolation(),
+			WithDefaultResolvers(),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, want, cfg.val1)
+	})
+	t.Run("report errors", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			values  map[string]interface{}
+			wantErr string
+		}{
+			{
+				name:    "missing key",
+				values:  map[string]interface{}{"val1": "${missing}"},
+				wantErr: "failed building config: failed to interpolate val1: missing is not set",
+			},
+			{
+				name:    "missing env",
+				values:  map[string]interface{}{"val1": "${env:TEST_MISSING_ENV}"},
+				wantErr: "failed building config: failed to interpolate val1: env:TEST_MISSING_ENV is not set",
+			},
+			{
+				name:    "unterminated",
+				values:  map[string]interface{}{"val1": "abc${api/host"},
+				wantErr: "failed building config: failed to interpolate val1: unterminated ${ at position 3",
+			},
+			{
+				name:    "not a scalar",
+				values:  map[string]interface{}{"val1": "${api}", "api": map[string]interface{}{"host": "h"}},
+				wantErr: "failed building config: failed to interpolate val1: api is not a scalar value",
+			},
+			{
+				name: "cycle",
+				values: map[string]interface{}{
+					"val1": "${a}",
+					"a":    "${b}",
+					"b":    "${a}",
+				},
+				wantErr: "failed building config: failed to interpolate val1: cycle detected: a -> b -> a",
+			},
+			{
+				name:    "self reference",
+				values:  map[string]interface{}{"val1": "${val1}"},
+				wantErr: "failed building config: failed to interpolate val1: cycle detected: val1 -> val1",
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := Load(testConfigFactory, withValues(tt.values), WithInterpolation())
+				assert.EqualError(t, err, tt.wantErr)
+			})
+		}
+	})
+}
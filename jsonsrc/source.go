@@ -1,20 +1,25 @@
 package jsonsrc
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path"
-	"strings"
+	"time"
 
 	"github.com/gocombo/config"
+	"github.com/gocombo/config/internal/keypath"
+	"github.com/gocombo/config/internal/poll"
 	"github.com/gocombo/config/val"
 )
 
 type loadOpts struct {
+	name              string
 	baseDir           string
 	ignoreMissingFile bool
+	pollInterval      time.Duration
 	openFile          func(fileName string) (file io.ReadCloser, err error)
 }
 
@@ -22,6 +27,7 @@ func defaultLoadOpts() loadOpts {
 	return loadOpts{
 		baseDir:           "",
 		ignoreMissingFile: false,
+		pollInterval:      poll.DefaultInterval,
 		openFile: func(fileName string) (file io.ReadCloser, err error) {
 			return os.Open(fileName)
 		},
@@ -36,6 +42,14 @@ func (o *loadOpts) set(optSetter []LoadOpt) {
 
 type LoadOpt func(opts *loadOpts)
 
+// WithName sets a name of the source that is used in errors
+// and value origin. Path of the file is used by default.
+func WithName(name string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.name = name
+	}
+}
+
 func WithBaseDir(baseDir string) LoadOpt {
 	return func(opts *loadOpts) {
 		opts.baseDir = baseDir
@@ -48,44 +62,61 @@ func IgnoreMissingFile() LoadOpt {
 	}
 }
 
-func getRawValue(key string, source map[string]interface{}) interface{} {
-	if source == nil {
-		return nil
-	}
-	firstSeparatorIndex := strings.Index(key, "/")
-	if firstSeparatorIndex >= 0 {
-		parentKey := key[:firstSeparatorIndex]
-		nestedKey := key[firstSeparatorIndex+1:]
-		if nestedSource, ok := source[parentKey].(map[string]interface{}); ok {
-			return getRawValue(
-				nestedKey,
-				nestedSource,
-			)
-		}
-	}
-	if v, ok := source[key]; ok {
-		return v
+// WithPollInterval defines how often the file is checked for changes
+// when config is watched
+func WithPollInterval(interval time.Duration) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.pollInterval = interval
 	}
-	return nil
 }
 
 type source struct {
-	rawValues map[string]interface{}
+	name         string
+	filePath     string
+	pollInterval time.Duration
+	rawValues    map[string]interface{}
+}
+
+func (src *source) Name() string {
+	return src.name
+}
+
+// Watch polls the file for changes
+func (src *source) Watch(ctx context.Context, notify func()) {
+	poll.Files(ctx, src.pollInterval, notify, src.filePath)
 }
 
 // TODO: Null value support
 func (src *source) GetValue(key string) (val.Raw, bool) {
-	if v := getRawValue(key, src.rawValues); v != nil {
-		return val.Raw{Key: key, Val: v}, true
+	if v := keypath.Lookup(key, src.rawValues); v != nil {
+		return val.Raw{
+			Key: key,
+			Val: v,
+			Origin: val.Origin{
+				Source:  src.name,
+				File:    src.filePath,
+				Pointer: keypath.JSONPointer(key),
+			},
+		}, true
 	}
 	return val.Raw{}, false
 }
 
+func (o *loadOpts) sourceName(filePath string) string {
+	if o.name != "" {
+		return o.name
+	}
+	return filePath
+}
+
 func Load(fileName string, optSetter ...LoadOpt) config.LoadOpt {
 	return func(opts config.LoadOpts) {
-		opts.AddSourceLoader(func() (config.Source, error) {
+		srcOpts := defaultLoadOpts()
+		srcOpts.set(optSetter)
+		name := srcOpts.sourceName(path.Join(srcOpts.baseDir, fileName))
+		opts.AddSourceLoader(config.NamedSourceLoader(name, func() (config.Source, error) {
 			return load(fileName, optSetter...)
-		})
+		}))
 	}
 }
 
@@ -93,16 +124,21 @@ func load(fileName string, optSetter ...LoadOpt) (config.Source, error) {
 	opts := defaultLoadOpts()
 	opts.set(optSetter)
 
-	file, err := opts.openFile(path.Join(opts.baseDir, fileName))
+	filePath := path.Join(opts.baseDir, fileName)
+	name := opts.sourceName(filePath)
+	file, err := opts.openFile(filePath)
 	if err != nil {
 		if opts.ignoreMissingFile && os.IsNotExist(err) {
-			return &source{}, nil
+			return &source{name: name, filePath: filePath, pollInterval: opts.pollInterval}, nil
 		}
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 	src := source{
-		rawValues: map[string]interface{}{},
+		name:         name,
+		filePath:     filePath,
+		pollInterval: opts.pollInterval,
+		rawValues:    map[string]interface{}{},
 	}
 	if err := json.NewDecoder(file).Decode(&src.rawValues); err != nil {
 		return nil, fmt.Errorf("failed to decode json: %w", err)
@@ -2,15 +2,19 @@ package jsonsrc
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/brianvoe/gofakeit/v6"
 	"github.com/gocombo/config"
+	"github.com/gocombo/config/val"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -77,8 +81,16 @@ func TestJsonSource(t *testing.T) {
 			return mockOpts.sourceLoaders[0]()
 		}
 		t.Run("fail if no such file", func(t *testing.T) {
-			_, err := loadFromOpts(gofakeit.Generate("{name}.json"))
+			fileName := gofakeit.Generate("{name}.json")
+			_, err := loadFromOpts(fileName)
 			assert.ErrorIs(t, err, os.ErrNotExist)
+			assert.Contains(t, err.Error(), fmt.Sprintf("failed to load %s: ", fileName))
+		})
+		t.Run("attribute errors to named source", func(t *testing.T) {
+			name := gofakeit.Word()
+			_, err := loadFromOpts(gofakeit.Generate("{name}.json"), WithName(name))
+			assert.ErrorIs(t, err, os.ErrNotExist)
+			assert.Contains(t, err.Error(), fmt.Sprintf("failed to load %s: ", name))
 		})
 		t.Run("optionally not fail if no such file", func(t *testing.T) {
 			source, err := loadFromOpts(gofakeit.Generate("{name}.json"), IgnoreMissingFile())
@@ -119,6 +131,22 @@ func TestJsonSource(t *testing.T) {
 			}
 			assert.Equal(t, wantFileName, gotFilePath)
 		})
+		t.Run("name source", func(t *testing.T) {
+			name := gofakeit.Word()
+			source, err := loadFromOpts(
+				gofakeit.Generate("{name}.json"),
+				WithName(name),
+				func(opts *loadOpts) {
+					opts.openFile = func(fileName string) (file io.ReadCloser, err error) {
+						return (*closableBuffer)(bytes.NewBufferString("{}")), nil
+					}
+				},
+			)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, name, source.(config.Named).Name())
+		})
 		t.Run("load from base dir", func(t *testing.T) {
 			wantFileName := gofakeit.Generate("{name}.json")
 			wantDir := gofakeit.Generate("/{name}/{name}")
@@ -160,6 +188,23 @@ func TestJsonSource(t *testing.T) {
 			assertVal("nested/str_val_1", mockValues.Nested.StrVal1)
 			assertVal("nested/str_val_2", mockValues.Nested.StrVal2)
 		})
+		t.Run("should return value origin", func(t *testing.T) {
+			wantFileName := gofakeit.Generate("{name}.json")
+			wantDir := gofakeit.Generate("/{name}/{name}")
+			source, err := load(wantFileName, WithBaseDir(wantDir), withMockValues(randomMockSourceValues()))
+			if !assert.NoError(t, err) {
+				return
+			}
+			gotVal, ok := source.GetValue("nested/str_val_1")
+			if !assert.True(t, ok, "Value nested/str_val_1 not found") {
+				return
+			}
+			assert.Equal(t, val.Origin{
+				Source:  path.Join(wantDir, wantFileName),
+				File:    path.Join(wantDir, wantFileName),
+				Pointer: "/nested/str_val_1",
+			}, gotVal.Origin)
+		})
 		t.Run("handle non existing data", func(t *testing.T) {
 			source, err := load("test.json", IgnoreMissingFile())
 			if !assert.NoError(t, err) {
@@ -171,4 +216,45 @@ func TestJsonSource(t *testing.T) {
 			}
 		})
 	})
+
+	t.Run("Watch", func(t *testing.T) {
+		t.Run("notify when file changes", func(t *testing.T) {
+			tmpDir := t.TempDir()
+			fileName := gofakeit.Generate("{name}.json")
+			writeValues := func(values mockSourceValues) {
+				data, err := json.Marshal(values)
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+				if err := os.WriteFile(filepath.Join(tmpDir, fileName), data, 0o644); !assert.NoError(t, err) {
+					t.FailNow()
+				}
+			}
+			writeValues(randomMockSourceValues())
+			source, err := load(fileName, WithBaseDir(tmpDir), WithPollInterval(time.Millisecond))
+			if !assert.NoError(t, err) {
+				return
+			}
+			watchable, ok := source.(config.Watchable)
+			if !assert.True(t, ok, "source is not watchable") {
+				return
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			notified := make(chan struct{}, 1)
+			go watchable.Watch(ctx, func() {
+				select {
+				case notified <- struct{}{}:
+				default:
+				}
+			})
+			time.Sleep(20 * time.Millisecond)
+			writeValues(randomMockSourceValues())
+			select {
+			case <-notified:
+			case <-time.After(time.Second):
+				assert.Fail(t, "change not notified")
+			}
+		})
+	})
 }
@@ -0,0 +1,183 @@
+package k8ssrc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultServiceAccountDir is where Kubernetes mounts service account
+// token, CA certificate and namespace of the pod
+const DefaultServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+type objectMeta struct {
+	Name            string `json:"name"`
+	ResourceVersion string `json:"resourceVersion"`
+}
+
+// object is a subset of ConfigMap and Secret fields used by the source
+type object struct {
+	Metadata objectMeta        `json:"metadata"`
+	Data     map[string]string `json:"data"`
+}
+
+type watchEvent struct {
+	Type   string `json:"type"`
+	Object object `json:"object"`
+}
+
+type client struct {
+	apiServer  string
+	namespace  string
+	token      string
+	tokenFile  string
+	httpClient *http.Client
+}
+
+// newClient configures the client from options falling back
+// to in-cluster configuration of the pod service account
+func newClient(opts *loadOpts) (*client, error) {
+	c := &client{
+		apiServer:  opts.apiServer,
+		namespace:  opts.namespace,
+		token:      opts.token,
+		httpClient: opts.client,
+	}
+	if c.apiServer == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, errors.New("not running in cluster, KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are not set")
+		}
+		c.apiServer = "https://" + net.JoinHostPort(host, port)
+	}
+	if c.namespace == "" {
+		data, err := os.ReadFile(filepath.Join(opts.serviceAccountDir, "namespace"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read namespace: %w", err)
+		}
+		c.namespace = strings.TrimSpace(string(data))
+	}
+	if c.token == "" {
+		// Token is read before each request since kubelet rotates it
+		c.tokenFile = filepath.Join(opts.serviceAccountDir, "token")
+	}
+	if c.httpClient == nil {
+		transport, err := serviceAccountTransport(opts.serviceAccountDir)
+		if err != nil {
+			return nil, err
+		}
+		c.httpClient = &http.Client{Transport: transport}
+	}
+	return c, nil
+}
+
+// serviceAccountTransport returns a transport trusting CA certificate of the service account
+func serviceAccountTransport(serviceAccountDir string) (*http.Transport, error) {
+	caCert, err := os.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, errors.New("failed to parse CA certificate")
+	}
+	transport := &http.Transport{Proxy: http.ProxyFromEnvironment}
+	if defaultTransport, ok := http.DefaultTransport.(*http.Transport); ok {
+		// Keep timeouts and other settings of the default transport
+		transport = defaultTransport.Clone()
+	}
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	return transport, nil
+}
+
+func (c *client) resourceURL(resource string) string {
+	return strings.TrimSuffix(c.apiServer, "/") +
+		"/api/v1/namespaces/" + url.PathEscape(c.namespace) + "/" + resource
+}
+
+func (c *client) do(ctx context.Context, reqURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	token := c.token
+	if c.tokenFile != "" {
+		data, err := os.ReadFile(c.tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		var status struct {
+			Message string `json:"message"`
+		}
+		if json.NewDecoder(io.LimitReader(res.Body, 1<<16)).Decode(&status) == nil && status.Message != "" {
+			return nil, fmt.Errorf("unexpected status: %s: %s", res.Status, status.Message)
+		}
+		return nil, fmt.Errorf("unexpected status: %s", res.Status)
+	}
+	return res, nil
+}
+
+func (c *client) get(ctx context.Context, resource, name string) (object, error) {
+	res, err := c.do(ctx, c.resourceURL(resource)+"/"+url.PathEscape(name))
+	if err != nil {
+		return object{}, err
+	}
+	defer res.Body.Close()
+	var obj object
+	if err := json.NewDecoder(res.Body).Decode(&obj); err != nil {
+		return object{}, fmt.Errorf("failed to decode %s: %w", resource, err)
+	}
+	return obj, nil
+}
+
+// watch streams events of the named object starting after resourceVersion
+// until the server closes the stream, ctx is done or handle returns false
+func (c *client) watch(ctx context.Context, resource, name, resourceVersion string, handle func(watchEvent) bool) error {
+	query := url.Values{
+		"watch":         {"true"},
+		"fieldSelector": {"metadata.name=" + name},
+	}
+	if resourceVersion != "" {
+		query.Set("resourceVersion", resourceVersion)
+	}
+	res, err := c.do(ctx, c.resourceURL(resource)+"?"+query.Encode())
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	decoder := json.NewDecoder(res.Body)
+	for {
+		var event watchEvent
+		if err := decoder.Decode(&event); err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if !handle(event) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,383 @@
+package k8ssrc
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gocombo/config"
+	"github.com/gocombo/config/internal/keypath"
+	"github.com/gocombo/config/internal/yamldoc"
+	"github.com/gocombo/config/val"
+)
+
+// DefaultTimeout is a timeout of a request reading the object
+const DefaultTimeout = 10 * time.Second
+
+// DefaultRetryInterval is used to retry watching
+// the object if the watch request has failed
+const DefaultRetryInterval = 5 * time.Second
+
+type payloadFormat int
+
+const (
+	formatJSON payloadFormat = iota
+	formatYAML
+)
+
+type payload struct {
+	dataKey string
+	format  payloadFormat
+}
+
+type loadOpts struct {
+	name              string
+	namespace         string
+	apiServer         string
+	token             string
+	client            *http.Client
+	timeout           time.Duration
+	retryInterval     time.Duration
+	serviceAccountDir string
+	dataKeyToKey      map[string]string
+	payloads          []payload
+}
+
+func defaultLoadOpts() loadOpts {
+	return loadOpts{
+		timeout:           DefaultTimeout,
+		retryInterval:     DefaultRetryInterval,
+		serviceAccountDir: DefaultServiceAccountDir,
+		dataKeyToKey:      map[string]string{},
+	}
+}
+
+func (o *loadOpts) set(optSetter []LoadOpt) {
+	for _, opt := range optSetter {
+		opt(o)
+	}
+}
+
+func (o *loadOpts) sourceName(kind, name string) string {
+	if o.name != "" {
+		return o.name
+	}
+	return kind + "/" + name
+}
+
+type LoadOpt func(opts *loadOpts)
+
+// WithName sets a name of the source that is used in errors
+// and value origin. Default name is kind and name of the object, e.g configmap/myapp.
+func WithName(name string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.name = name
+	}
+}
+
+// WithNamespace sets a namespace of the object. Namespace of the pod is used by default.
+func WithNamespace(namespace string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.namespace = namespace
+	}
+}
+
+// WithAPIServer sets an address of the API server.
+// In-cluster address is used by default.
+func WithAPIServer(address string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.apiServer = address
+	}
+}
+
+// WithToken sets a bearer token to authenticate with.
+// Token of the pod service account is used by default.
+func WithToken(token string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.token = token
+	}
+}
+
+// WithClient sets a client that is used to send requests. By default
+// a client trusting CA certificate of the pod service account is used.
+func WithClient(client *http.Client) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.client = client
+	}
+}
+
+// WithTimeout sets a timeout of a request reading the object. Default is DefaultTimeout,
+// non-positive values are ignored.
+func WithTimeout(timeout time.Duration) LoadOpt {
+	return func(opts *loadOpts) {
+		if timeout > 0 {
+			opts.timeout = timeout
+		}
+	}
+}
+
+// WithRetryInterval sets how long to wait before watching the object again
+// if watch request has failed. Default is DefaultRetryInterval, non-positive
+// values are ignored.
+func WithRetryInterval(interval time.Duration) LoadOpt {
+	return func(opts *loadOpts) {
+		if interval > 0 {
+			opts.retryInterval = interval
+		}
+	}
+}
+
+// ParseJSON parses the data key as a JSON object. Values of the object
+// are available at the root, e.g server/port for {"server": {"port": 8080}}.
+func ParseJSON(dataKey string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.payloads = append(opts.payloads, payload{dataKey: dataKey, format: formatJSON})
+	}
+}
+
+// ParseYAML is same as ParseJSON but parses a YAML mapping
+func ParseYAML(dataKey string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.payloads = append(opts.payloads, payload{dataKey: dataKey, format: formatYAML})
+	}
+}
+
+type LoadValOptBuilder struct {
+	valuePath string
+}
+
+// From defines a data key to set value from
+func (b *LoadValOptBuilder) From(dataKey string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.dataKeyToKey[dataKey] = b.valuePath
+	}
+}
+
+// Set config value using From. Data keys that are not mapped
+// explicitly are available using the data key as a key.
+func Set(path string) *LoadValOptBuilder {
+	return &LoadValOptBuilder{
+		valuePath: path,
+	}
+}
+
+type source struct {
+	name            string
+	resource        string
+	objectName      string
+	resourceVersion string
+	timeout         time.Duration
+	retryInterval   time.Duration
+	client          *client
+
+	// valuesByKey are data keys mapped explicitly with Set
+	valuesByKey map[string]val.Raw
+	rawValues   map[string]interface{}
+}
+
+func (src *source) Name() string {
+	return src.name
+}
+
+func (src *source) GetValue(key string) (val.Raw, bool) {
+	if rawVal, ok := src.valuesByKey[key]; ok {
+		return rawVal, true
+	}
+	if v := keypath.Lookup(key, src.rawValues); v != nil {
+		return val.Raw{
+			Key: key,
+			Val: v,
+			Origin: val.Origin{
+				Source: src.name,
+			},
+		}, true
+	}
+	return val.Raw{}, false
+}
+
+// Watch uses the watch endpoint of the API server to get notified when
+// the object is changed or deleted. Watch is restarted after retry interval
+// when the server closes the stream or the watch request has failed.
+func (src *source) Watch(ctx context.Context, notify func()) {
+	resourceVersion := src.resourceVersion
+	for {
+		var expired bool
+		var err error
+		resourceVersion, expired, err = src.watchChanges(ctx, resourceVersion, notify)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil && expired {
+			resourceVersion = src.resync(ctx, resourceVersion, notify)
+		}
+		// Wait even if the stream was closed without errors, so a server
+		// or a proxy closing it right away does not cause a loop of requests
+		timer := time.NewTimer(src.retryInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// watchChanges notifies changes of the object until the watch stream is closed.
+// Expired is true if the server reported an error, most likely resource version
+// is too old to watch from.
+func (src *source) watchChanges(
+	ctx context.Context, resourceVersion string, notify func(),
+) (lastVersion string, expired bool, err error) {
+	err = src.client.watch(ctx, src.resource, src.objectName, resourceVersion, func(event watchEvent) bool {
+		if event.Type == "ERROR" {
+			expired = true
+			return false
+		}
+		if event.Object.Metadata.ResourceVersion != resourceVersion {
+			resourceVersion = event.Object.Metadata.ResourceVersion
+			notify()
+		}
+		return true
+	})
+	return resourceVersion, expired, err
+}
+
+// resync compares the current state of the object with the resource version
+// so it's watched from there, the same version is returned on failure
+func (src *source) resync(ctx context.Context, resourceVersion string, notify func()) string {
+	getCtx, cancel := context.WithTimeout(ctx, src.timeout)
+	defer cancel()
+	obj, err := src.client.get(getCtx, src.resource, src.objectName)
+	if err != nil || obj.Metadata.ResourceVersion == resourceVersion {
+		return resourceVersion
+	}
+	notify()
+	return obj.Metadata.ResourceVersion
+}
+
+func decodePayload(p payload, data []byte) (map[string]interface{}, error) {
+	switch p.format {
+	case formatYAML:
+		return yamldoc.Decode(bytes.NewReader(data))
+	default:
+		values := map[string]interface{}{}
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil, err
+		}
+		return values, nil
+	}
+}
+
+// LoadConfigMap reads data of the named ConfigMap using API server.
+// Every data key is available as a config key unless mapped with Set
+// or parsed with ParseJSON or ParseYAML.
+func LoadConfigMap(name string, optSetter ...LoadOpt) config.LoadOpt {
+	return loadOpt("configmaps", "configmap", name, optSetter)
+}
+
+// LoadSecret is same as LoadConfigMap but reads the named Secret
+func LoadSecret(name string, optSetter ...LoadOpt) config.LoadOpt {
+	return loadOpt("secrets", "secret", name, optSetter)
+}
+
+func loadOpt(resource, kind, name string, optSetter []LoadOpt) config.LoadOpt {
+	return func(opts config.LoadOpts) {
+		srcOpts := defaultLoadOpts()
+		srcOpts.set(optSetter)
+		opts.AddSourceLoader(config.NamedSourceLoader(srcOpts.sourceName(kind, name), func() (config.Source, error) {
+			return load(resource, kind, name, optSetter...)
+		}))
+	}
+}
+
+// objectData returns data of the object, values of secrets are decoded
+func objectData(resource string, obj object) (map[string][]byte, error) {
+	data := make(map[string][]byte, len(obj.Data))
+	for dataKey, value := range obj.Data {
+		if resource != "secrets" {
+			data[dataKey] = []byte(value)
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", dataKey, err)
+		}
+		data[dataKey] = decoded
+	}
+	return data, nil
+}
+
+// setData sets values of parsed payloads and other data keys
+func (src *source) setData(opts *loadOpts, data map[string][]byte) error {
+	parsed := map[string]bool{}
+	for _, p := range opts.payloads {
+		parsed[p.dataKey] = true
+		payloadData, ok := data[p.dataKey]
+		if !ok {
+			continue
+		}
+		values, err := decodePayload(p, payloadData)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", p.dataKey, err)
+		}
+		for key, value := range values {
+			src.rawValues[key] = value
+		}
+	}
+	for dataKey, value := range data {
+		if parsed[dataKey] {
+			continue
+		}
+		key, ok := opts.dataKeyToKey[dataKey]
+		if !ok {
+			src.rawValues[dataKey] = string(value)
+			continue
+		}
+		src.valuesByKey[key] = val.Raw{
+			Key: key,
+			Val: string(value),
+			Origin: val.Origin{
+				Source: src.name,
+			},
+		}
+	}
+	return nil
+}
+
+func load(resource, kind, name string, optSetter ...LoadOpt) (config.Source, error) {
+	opts := defaultLoadOpts()
+	opts.set(optSetter)
+	c, err := newClient(&opts)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout)
+	defer cancel()
+	obj, err := c.get(ctx, resource, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %s: %w", kind, name, err)
+	}
+	data, err := objectData(resource, obj)
+	if err != nil {
+		return nil, err
+	}
+	src := &source{
+		name:            opts.sourceName(kind, name),
+		resource:        resource,
+		objectName:      name,
+		resourceVersion: obj.Metadata.ResourceVersion,
+		timeout:         opts.timeout,
+		retryInterval:   opts.retryInterval,
+		client:          c,
+		valuesByKey:     map[string]val.Raw{},
+		rawValues:       map[string]interface{}{},
+	}
+	if err = src.setData(&opts, data); err != nil {
+		return nil, err
+	}
+	return src, nil
+}
@@ -0,0 +1,416 @@
+package k8ssrc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/gocombo/config"
+	"github.com/gocombo/config/val"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockLoadOpts struct {
+	sourceLoaders []config.SourceLoader
+}
+
+func (m *mockLoadOpts) AddSourceLoader(loader config.SourceLoader) {
+	m.sourceLoaders = append(m.sourceLoaders, loader)
+}
+
+// mockAPIServer is a minimal fake of Kubernetes API server
+// serving a single ConfigMap or Secret
+type mockAPIServer struct {
+	mu               sync.Mutex
+	namespace        string
+	resource         string
+	obj              object
+	changed          chan struct{}
+	watchRequests    []url.Values
+	gotAuthorization string
+
+	// closeWatch makes watch requests end right away without events
+	closeWatch bool
+}
+
+func newMockAPIServer(resource string) *mockAPIServer {
+	return &mockAPIServer{
+		namespace: gofakeit.Word(),
+		resource:  resource,
+		obj: object{
+			Metadata: objectMeta{Name: gofakeit.Word(), ResourceVersion: "1"},
+		},
+		changed: make(chan struct{}),
+	}
+}
+
+func (m *mockAPIServer) set(data map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.resource == "secrets" {
+		encoded := make(map[string]string, len(data))
+		for key, value := range data {
+			encoded[key] = base64.StdEncoding.EncodeToString([]byte(value))
+		}
+		data = encoded
+	}
+	m.obj.Data = data
+	rv, _ := strconv.Atoi(m.obj.Metadata.ResourceVersion)
+	m.obj.Metadata.ResourceVersion = strconv.Itoa(rv + 1)
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+func (m *mockAPIServer) watchRequestsCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.watchRequests)
+}
+
+func (m *mockAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	m.gotAuthorization = r.Header.Get("Authorization")
+	basePath := "/api/v1/namespaces/" + m.namespace + "/" + m.resource
+	obj := m.obj
+	m.mu.Unlock()
+	switch r.URL.Path {
+	case basePath + "/" + obj.Metadata.Name:
+		json.NewEncoder(w).Encode(obj)
+	case basePath:
+		m.serveWatch(w, r)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "not found"})
+	}
+}
+
+func (m *mockAPIServer) serveWatch(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	m.watchRequests = append(m.watchRequests, r.URL.Query())
+	obj := m.obj
+	changed := m.changed
+	closeWatch := m.closeWatch
+	m.mu.Unlock()
+	query := r.URL.Query()
+	if query.Get("fieldSelector") != "metadata.name="+obj.Metadata.Name {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	encoder := json.NewEncoder(w)
+	if query.Get("resourceVersion") == "expired" {
+		encoder.Encode(map[string]interface{}{"type": "ERROR", "object": map[string]interface{}{"code": 410}})
+		return
+	}
+	w.(http.Flusher).Flush()
+	if closeWatch {
+		return
+	}
+	select {
+	case <-r.Context().Done():
+		return
+	case <-changed:
+	}
+	m.mu.Lock()
+	obj = m.obj
+	m.mu.Unlock()
+	encoder.Encode(watchEvent{Type: "MODIFIED", Object: obj})
+	w.(http.Flusher).Flush()
+	<-r.Context().Done()
+}
+
+func TestK8sSource(t *testing.T) {
+	startAPIServer := func(t *testing.T, resource string) (*mockAPIServer, *httptest.Server) {
+		mock := newMockAPIServer(resource)
+		srv := httptest.NewServer(mock)
+		t.Cleanup(srv.Close)
+		return mock, srv
+	}
+	mockServerOpts := func(mock *mockAPIServer, srv *httptest.Server) []LoadOpt {
+		return []LoadOpt{
+			WithAPIServer(srv.URL),
+			WithNamespace(mock.namespace),
+			WithToken(gofakeit.UUID()),
+			WithClient(srv.Client()),
+		}
+	}
+	loadFromOpts := func(loadOpt func(string, ...LoadOpt) config.LoadOpt, name string, opts ...LoadOpt) (config.Source, error) {
+		mockOpts := &mockLoadOpts{}
+		loadOpt(name, opts...)(mockOpts)
+		if len(mockOpts.sourceLoaders) < 1 {
+			return nil, fmt.Errorf("no source loader added to opts")
+		}
+		return mockOpts.sourceLoaders[0]()
+	}
+	assertVal := func(t *testing.T, source config.Source, key string, wantVal interface{}) {
+		got, ok := source.GetValue(key)
+		if !assert.True(t, ok, "key %s not found", key) {
+			return
+		}
+		assert.Equal(t, wantVal, got.Val)
+	}
+
+	t.Run("LoadConfigMap", func(t *testing.T) {
+		t.Run("read data keys", func(t *testing.T) {
+			mock, srv := startAPIServer(t, "configmaps")
+			val1 := gofakeit.SentenceSimple()
+			val2 := gofakeit.SentenceSimple()
+			mock.set(map[string]string{"key1": val1, "log.level": val2})
+			token := gofakeit.UUID()
+			source, err := loadFromOpts(LoadConfigMap, mock.obj.Metadata.Name,
+				append(mockServerOpts(mock, srv), WithToken(token))...)
+			if !assert.NoError(t, err) {
+				return
+			}
+			got, ok := source.GetValue("key1")
+			if assert.True(t, ok, "key key1 not found") {
+				assert.Equal(t, val.Raw{
+					Key:    "key1",
+					Val:    val1,
+					Origin: val.Origin{Source: "configmap/" + mock.obj.Metadata.Name},
+				}, got)
+			}
+			assertVal(t, source, "log.level", val2)
+			assert.Equal(t, "Bearer "+token, mock.gotAuthorization)
+		})
+		t.Run("map data keys", func(t *testing.T) {
+			mock, srv := startAPIServer(t, "configmaps")
+			val1 := gofakeit.SentenceSimple()
+			mock.set(map[string]string{"SERVER_PORT": val1})
+			source, err := loadFromOpts(LoadConfigMap, mock.obj.Metadata.Name,
+				append(mockServerOpts(mock, srv), Set("server/port").From("SERVER_PORT"))...)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assertVal(t, source, "server/port", val1)
+			_, ok := source.GetValue("SERVER_PORT")
+			assert.False(t, ok, "mapped data key is present by its name")
+		})
+		t.Run("parse payloads", func(t *testing.T) {
+			mock, srv := startAPIServer(t, "configmaps")
+			mock.set(map[string]string{
+				"config.json": `{"server": {"port": 8080}}`,
+				"config.yaml": "db:\n  host: localhost\n",
+			})
+			source, err := loadFromOpts(LoadConfigMap, mock.obj.Metadata.Name,
+				append(mockServerOpts(mock, srv), ParseJSON("config.json"), ParseYAML("config.yaml"))...)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assertVal(t, source, "server/port", 8080.0)
+			assertVal(t, source, "db/host", "localhost")
+			_, ok := source.GetValue("config.json")
+			assert.False(t, ok, "parsed data key is present by its name")
+		})
+		t.Run("fail to parse invalid payload", func(t *testing.T) {
+			mock, srv := startAPIServer(t, "configmaps")
+			mock.set(map[string]string{"config.yaml": "- not a mapping"})
+			_, err := loadFromOpts(LoadConfigMap, mock.obj.Metadata.Name,
+				append(mockServerOpts(mock, srv), ParseYAML("config.yaml"))...)
+			assert.EqualError(t, err, fmt.Sprintf(
+				"failed to load configmap/%s: failed to parse config.yaml: expected a mapping, got []interface {}",
+				mock.obj.Metadata.Name,
+			))
+		})
+		t.Run("fail if not found", func(t *testing.T) {
+			mock, srv := startAPIServer(t, "configmaps")
+			name := gofakeit.Word() + "-missing"
+			_, err := loadFromOpts(LoadConfigMap, name, append(mockServerOpts(mock, srv), WithName("cfg"))...)
+			assert.EqualError(t, err, fmt.Sprintf(
+				"failed to load cfg: failed to get configmap %s: unexpected status: 404 Not Found: not found", name,
+			))
+		})
+		t.Run("fail on timeout", func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+			}))
+			defer srv.Close()
+			_, err := loadFromOpts(LoadConfigMap, gofakeit.Word(),
+				WithAPIServer(srv.URL),
+				WithNamespace(gofakeit.Word()),
+				WithToken(gofakeit.UUID()),
+				WithClient(srv.Client()),
+				WithTimeout(10*time.Millisecond),
+			)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		})
+		t.Run("use default timeout if not positive", func(t *testing.T) {
+			mock, srv := startAPIServer(t, "configmaps")
+			mock.set(map[string]string{"key1": gofakeit.SentenceSimple()})
+			for _, timeout := range []time.Duration{0, -time.Second} {
+				_, err := loadFromOpts(LoadConfigMap, mock.obj.Metadata.Name,
+					append(mockServerOpts(mock, srv), WithTimeout(timeout))...)
+				assert.NoError(t, err)
+			}
+		})
+	})
+
+	t.Run("LoadSecret", func(t *testing.T) {
+		t.Run("decode data keys", func(t *testing.T) {
+			mock, srv := startAPIServer(t, "secrets")
+			password := gofakeit.Password(true, true, true, true, false, 16)
+			mock.set(map[string]string{"password": password})
+			source, err := loadFromOpts(LoadSecret, mock.obj.Metadata.Name,
+				append(mockServerOpts(mock, srv), Set("db/password").From("password"))...)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assertVal(t, source, "db/password", password)
+			assert.Equal(t, "secret/"+mock.obj.Metadata.Name, source.(config.Named).Name())
+		})
+	})
+
+	t.Run("in cluster", func(t *testing.T) {
+		t.Run("use pod service account", func(t *testing.T) {
+			mock := newMockAPIServer("configmaps")
+			srv := httptest.NewTLSServer(mock)
+			defer srv.Close()
+			val1 := gofakeit.SentenceSimple()
+			mock.set(map[string]string{"key1": val1})
+
+			saDir := t.TempDir()
+			token := gofakeit.UUID()
+			caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+			for fileName, content := range map[string][]byte{
+				"token":     []byte(token + "\n"),
+				"namespace": []byte(mock.namespace),
+				"ca.crt":    caCert,
+			} {
+				if !assert.NoError(t, os.WriteFile(filepath.Join(saDir, fileName), content, 0o600)) {
+					return
+				}
+			}
+			srvURL, _ := url.Parse(srv.URL)
+			t.Setenv("KUBERNETES_SERVICE_HOST", srvURL.Hostname())
+			t.Setenv("KUBERNETES_SERVICE_PORT", srvURL.Port())
+
+			source, err := loadFromOpts(LoadConfigMap, mock.obj.Metadata.Name, func(opts *loadOpts) {
+				opts.serviceAccountDir = saDir
+			})
+			if !assert.NoError(t, err) {
+				return
+			}
+			assertVal(t, source, "key1", val1)
+			assert.Equal(t, "Bearer "+token, mock.gotAuthorization)
+		})
+		t.Run("fail if not in cluster", func(t *testing.T) {
+			t.Setenv("KUBERNETES_SERVICE_HOST", "")
+			_, err := loadFromOpts(LoadConfigMap, gofakeit.Word(), WithName("cfg"))
+			assert.EqualError(t, err, "failed to load cfg: not running in cluster, "+
+				"KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are not set")
+		})
+	})
+
+	t.Run("Watch", func(t *testing.T) {
+		watch := func(t *testing.T, source config.Source) <-chan struct{} {
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			notified := make(chan struct{}, 1)
+			go source.(config.Watchable).Watch(ctx, func() {
+				select {
+				case notified <- struct{}{}:
+				default:
+				}
+			})
+			return notified
+		}
+		assertNotified := func(t *testing.T, notified <-chan struct{}) {
+			select {
+			case <-notified:
+			case <-time.After(time.Second):
+				assert.Fail(t, "change not notified")
+			}
+		}
+		t.Run("notify when object changes", func(t *testing.T) {
+			mock, srv := startAPIServer(t, "configmaps")
+			mock.set(map[string]string{"key1": gofakeit.SentenceSimple()})
+			source, err := load("configmaps", "configmap", mock.obj.Metadata.Name, mockServerOpts(mock, srv)...)
+			if !assert.NoError(t, err) {
+				return
+			}
+			notified := watch(t, source)
+			assert.Eventually(t, func() bool {
+				return mock.watchRequestsCount() == 1
+			}, time.Second, time.Millisecond)
+			mock.mu.Lock()
+			query := mock.watchRequests[0]
+			mock.mu.Unlock()
+			assert.Equal(t, "2", query.Get("resourceVersion"))
+			assert.Equal(t, "true", query.Get("watch"))
+			mock.set(map[string]string{"key1": gofakeit.SentenceSimple()})
+			assertNotified(t, notified)
+		})
+		t.Run("restart watch if resource version expired", func(t *testing.T) {
+			mock, srv := startAPIServer(t, "configmaps")
+			mock.set(map[string]string{"key1": gofakeit.SentenceSimple()})
+			src, err := load("configmaps", "configmap", mock.obj.Metadata.Name,
+				append(mockServerOpts(mock, srv), WithRetryInterval(time.Millisecond))...)
+			if !assert.NoError(t, err) {
+				return
+			}
+			src.(*source).resourceVersion = "expired"
+			notified := watch(t, src)
+			assertNotified(t, notified)
+			assert.Eventually(t, func() bool {
+				return mock.watchRequestsCount() == 2
+			}, time.Second, time.Millisecond)
+			mock.mu.Lock()
+			query := mock.watchRequests[1]
+			mock.mu.Unlock()
+			assert.Equal(t, "2", query.Get("resourceVersion"))
+		})
+		t.Run("retry failed watch", func(t *testing.T) {
+			mock, srv := startAPIServer(t, "configmaps")
+			mock.set(map[string]string{"key1": gofakeit.SentenceSimple()})
+			src, err := load("configmaps", "configmap", mock.obj.Metadata.Name,
+				append(mockServerOpts(mock, srv), WithRetryInterval(time.Millisecond))...)
+			if !assert.NoError(t, err) {
+				return
+			}
+			src.(*source).objectName = gofakeit.Word() + "-missing"
+			watch(t, src)
+			assert.Eventually(t, func() bool {
+				return mock.watchRequestsCount() > 2
+			}, time.Second, time.Millisecond)
+		})
+		t.Run("wait before watching again if stream is closed", func(t *testing.T) {
+			mock, srv := startAPIServer(t, "configmaps")
+			mock.set(map[string]string{"key1": gofakeit.SentenceSimple()})
+			mock.closeWatch = true
+			src, err := load("configmaps", "configmap", mock.obj.Metadata.Name, mockServerOpts(mock, srv)...)
+			if !assert.NoError(t, err) {
+				return
+			}
+			watch(t, src)
+			assert.Eventually(t, func() bool {
+				return mock.watchRequestsCount() == 1
+			}, time.Second, time.Millisecond)
+			time.Sleep(20 * time.Millisecond)
+			assert.Equal(t, 1, mock.watchRequestsCount(), "watch restarted without interval")
+		})
+		t.Run("use default retry interval if not positive", func(t *testing.T) {
+			mock, srv := startAPIServer(t, "configmaps")
+			mock.set(map[string]string{"key1": gofakeit.SentenceSimple()})
+			src, err := load("configmaps", "configmap", mock.obj.Metadata.Name,
+				append(mockServerOpts(mock, srv), WithRetryInterval(0))...)
+			if !assert.NoError(t, err) {
+				return
+			}
+			src.(*source).objectName = gofakeit.Word() + "-missing"
+			watch(t, src)
+			time.Sleep(20 * time.Millisecond)
+			assert.Equal(t, 1, mock.watchRequestsCount(), "watch retried without interval")
+		})
+	})
+}
@@ -0,0 +1,132 @@
+package config
+
+import "github.com/gocombo/config/val"
+
+// ArrayMergeStrategy defines how arrays are merged when deep merge is enabled
+type ArrayMergeStrategy int
+
+const (
+	// ArrayReplace uses the array of a higher priority source as is
+	ArrayReplace ArrayMergeStrategy = iota
+
+	// ArrayAppend appends items of a higher priority source
+	// to items of a lower priority one
+	ArrayAppend
+
+	// ArrayMergeByIndex deep merges items with the same index,
+	// extra items of a higher priority source are appended
+	ArrayMergeByIndex
+)
+
+// WithDeepMerge makes object values be deep merged across all sources
+// that define them, lowest priority first. Without it the object of the
+// highest priority source is used as is. A non object value of a source
+// shadows objects of all lower priority sources.
+func WithDeepMerge(arrays ArrayMergeStrategy) LoadOpt {
+	return withLoadOpts(func(opts *loadOpts) {
+		opts.deepMerge = true
+		opts.arrayMergeStrategy = arrays
+	})
+}
+
+func copyValue(v interface{}) interface{} {
+	switch actualVal := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(actualVal))
+		for key, item := range actualVal {
+			result[key] = copyValue(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(actualVal))
+		for i, item := range actualVal {
+			result[i] = copyValue(item)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// mergeValues merges override into base. Base is modified
+// and must not be shared with a source.
+func mergeValues(base, override interface{}, arrays ArrayMergeStrategy) interface{} {
+	switch overrideVal := override.(type) {
+	case map[string]interface{}:
+		baseVal, ok := base.(map[string]interface{})
+		if !ok {
+			return copyValue(override)
+		}
+		return mergeMaps(baseVal, overrideVal, arrays)
+	case []interface{}:
+		baseVal, ok := base.([]interface{})
+		if !ok {
+			return copyValue(override)
+		}
+		return mergeArrays(baseVal, overrideVal, arrays)
+	default:
+		return override
+	}
+}
+
+func mergeMaps(base, override map[string]interface{}, arrays ArrayMergeStrategy) map[string]interface{} {
+	for key, item := range override {
+		if baseItem, ok := base[key]; ok {
+			base[key] = mergeValues(baseItem, item, arrays)
+			continue
+		}
+		base[key] = copyValue(item)
+	}
+	return base
+}
+
+func mergeArrays(base, override []interface{}, arrays ArrayMergeStrategy) interface{} {
+	switch arrays {
+	case ArrayAppend:
+		for _, item := range override {
+			base = append(base, copyValue(item))
+		}
+		return base
+	case ArrayMergeByIndex:
+		for i, item := range override {
+			if i < len(base) {
+				base[i] = mergeValues(base[i], item, arrays)
+				continue
+			}
+			base = append(base, copyValue(item))
+		}
+		return base
+	default:
+		return copyValue(override)
+	}
+}
+
+// lookupMerged returns deep merged value of all sources
+// if the value of the highest priority source is an object
+func (p *valuesProvider) lookupMerged(key string) (val.Raw, bool) {
+	var candidates []val.Raw
+	for i := range p.sources {
+		src := p.sources[len(p.sources)-1-i]
+		v, ok := src.GetValue(key)
+		if !ok {
+			continue
+		}
+		if _, isObject := v.Val.(map[string]interface{}); !isObject {
+			if len(candidates) == 0 {
+				return v, true
+			}
+			break
+		}
+		candidates = append(candidates, v)
+	}
+	if len(candidates) == 0 {
+		return val.Raw{}, false
+	}
+	result := candidates[0]
+	merged := copyValue(candidates[len(candidates)-1].Val)
+	for i := len(candidates) - 2; i >= 0; i-- {
+		merged = mergeValues(merged, candidates[i].Val, p.arrayMergeStrategy)
+	}
+	result.Val = merged
+	return result, true
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/gocombo/config/val"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeepMerge(t *testing.T) {
+	type server struct {
+		Host  string   `json:"host"`
+		Port  int      `json:"port"`
+		Tags  []string `json:"tags"`
+		Debug bool     `json:"debug"`
+	}
+	type config struct {
+		server server
+	}
+	testConfigFactory := func(p val.Provider) *config {
+		return &config{
+			server: val.Define[server](p, "server"),
+		}
+	}
+	withValues := func(values map[string]interface{}) LoadOpt {
+		return func(opts LoadOpts) {
+			opts.AddSourceLoader(func() (Source, error) {
+				raw := map[string]val.Raw{}
+				for key, v := range values {
+					raw[key] = val.Raw{Key: key, Val: v}
+				}
+				return &mockKeyValueSource{values: raw}, nil
+			})
+		}
+	}
+
+	t.Run("use highest priority object by default", func(t *testing.T) {
+		wantPort := gofakeit.Number(1000, 9000)
+		got, err := Load(testConfigFactory,
+			withValues(map[string]interface{}{
+				"server": map[string]interface{}{"host": gofakeit.DomainName(), "port": 80},
+			}),
+			withValues(map[string]interface{}{
+				"server": map[string]interface{}{"port": wantPort},
+			}),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, server{Port: wantPort}, got.server)
+	})
+
+	t.Run("merge objects lowest priority first", func(t *testing.T) {
+		wantHost := gofakeit.DomainName()
+		wantPort := gofakeit.Number(1000, 9000)
+		defaultServer := map[string]interface{}{
+			"host":  wantHost,
+			"port":  80,
+			"debug": true,
+			"tags":  []interface{}{"a", "b"},
+		}
+		got, err := Load(testConfigFactory,
+			withValues(map[string]interface{}{"server": defaultServer}),
+			withValues(map[string]interface{}{
+				"server": map[string]interface{}{"port": wantPort, "tags": []interface{}{"c"}},
+			}),
+			withValues(map[string]interface{}{
+				"server": map[string]interface{}{"debug": false},
+			}),
+			WithDeepMerge(ArrayReplace),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, server{Host: wantHost, Port: wantPort, Tags: []string{"c"}}, got.server)
+		assert.Equal(t, 80, defaultServer["port"], "source value must not be modified")
+	})
+
+	t.Run("non object value shadows lower priority objects", func(t *testing.T) {
+		wantPort := gofakeit.Number(1000, 9000)
+		got, err := Load(testConfigFactory,
+			withValues(map[string]interface{}{
+				"server": map[string]interface{}{"host": gofakeit.DomainName()},
+			}),
+			withValues(map[string]interface{}{
+				"server": `{"port": 80}`,
+			}),
+			withValues(map[string]interface{}{
+				"server": map[string]interface{}{"port": wantPort},
+			}),
+			WithDeepMerge(ArrayReplace),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, server{Port: wantPort}, got.server)
+	})
+
+	t.Run("array strategies", func(t *testing.T) {
+		type item struct {
+			Name  string `json:"name"`
+			Value int    `json:"value"`
+		}
+		type config struct {
+			items []item
+		}
+		testConfigFactory := func(p val.Provider) *config {
+			return &config{
+				items: val.Define[map[string][]item](p, "obj")["items"],
+			}
+		}
+		lowSource := withValues(map[string]interface{}{
+			"obj": map[string]interface{}{"items": []interface{}{
+				map[string]interface{}{"name": "a", "value": 1},
+				map[string]interface{}{"name": "b", "value": 2},
+			}},
+		})
+		highSource := withValues(map[string]interface{}{
+			"obj": map[string]interface{}{"items": []interface{}{
+				map[string]interface{}{"value": 3},
+			}},
+		})
+		tests := []struct {
+			name     string
+			strategy ArrayMergeStrategy
+			want     []item
+		}{
+			{"replace", ArrayReplace, []item{{Value: 3}}},
+			{"append", ArrayAppend, []item{{"a", 1}, {"b", 2}, {"", 3}}},
+			{"merge by index", ArrayMergeByIndex, []item{{"a", 3}, {"b", 2}}},
+		}
+		for _, tt := range tests {
+			tt := tt
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := Load(testConfigFactory, lowSource, highSource, WithDeepMerge(tt.strategy))
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.Equal(t, tt.want, got.items)
+			})
+		}
+	})
+}
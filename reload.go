@@ -0,0 +1,149 @@
+package config
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WithReloadInterval makes Watch reload the config periodically
+func WithReloadInterval(interval time.Duration) LoadOpt {
+	return withLoadOpts(func(opts *loadOpts) {
+		opts.reloadInterval = interval
+	})
+}
+
+// OnReloadError sets a handler that is called by Watch
+// if config failed to reload. Last good config is kept in such case.
+func OnReloadError(handler func(err error)) LoadOpt {
+	return withLoadOpts(func(opts *loadOpts) {
+		opts.onReloadError = handler
+	})
+}
+
+// Reloader holds the last successfully built config snapshot
+// and allows rebuilding it from sources
+type Reloader[T any] struct {
+	factory configFactory[T]
+	opts    *loadOpts
+	current atomic.Pointer[T]
+
+	// reloadMu makes sure reloads are not running concurrently
+	reloadMu sync.Mutex
+
+	// sources of the current config
+	sources []Source
+
+	// reloaded signals Watch to start watching sources of the reloaded config
+	reloaded     chan struct{}
+	stopWatchers context.CancelFunc
+}
+
+// Current returns the last successfully built config
+func (r *Reloader[T]) Current() *T {
+	return r.current.Load()
+}
+
+// Reload loads all sources and builds the config again.
+// The new config is published only if it was built successfully,
+// otherwise the last good config is kept and the error is returned.
+func (r *Reloader[T]) Reload() error {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+	sources, err := r.opts.loadSources()
+	if err != nil {
+		return err
+	}
+	cfg, err := build(r.factory, newValuesProvider(sources, r.opts), r.opts.validators)
+	if err != nil {
+		return err
+	}
+	r.sources = sources
+	r.current.Store(cfg)
+	select {
+	case r.reloaded <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// startWatchers stops watching previous sources and starts watching the current ones
+func (r *Reloader[T]) startWatchers(ctx context.Context, notify func()) {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+	if r.stopWatchers != nil {
+		r.stopWatchers()
+	}
+	ctx, r.stopWatchers = context.WithCancel(ctx)
+	for _, src := range r.sources {
+		if watchable, ok := src.(Watchable); ok {
+			go watchable.Watch(ctx, notify)
+		}
+	}
+}
+
+func (r *Reloader[T]) watch(ctx context.Context) {
+	// Buffer of one is enough since a single reload
+	// will pick all changes that happened before it
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+
+	r.startWatchers(ctx, notify)
+
+	var tick <-chan time.Time
+	if r.opts.reloadInterval > 0 {
+		ticker := time.NewTicker(r.opts.reloadInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.reloaded:
+			// Sources are replaced on every successful reload
+			r.startWatchers(ctx, notify)
+			continue
+		case <-tick:
+		case <-changed:
+		}
+		if err := r.Reload(); err != nil && r.opts.onReloadError != nil {
+			r.opts.onReloadError(err)
+		}
+	}
+}
+
+// NewReloader loads the config same way as Load does and returns
+// a Reloader that can be used to reload it later
+func NewReloader[T any](factory configFactory[T], optsSetters ...LoadOpt) (*Reloader[T], error) {
+	r := &Reloader[T]{
+		factory:  factory,
+		opts:     newLoadOpts(optsSetters),
+		reloaded: make(chan struct{}, 1),
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	// Sources of the initial config are watched when Watch starts
+	<-r.reloaded
+	return r, nil
+}
+
+// Watch loads the config and keeps reloading it in background until ctx is done.
+// Config is reloaded when any of the sources implementing Watchable notifies
+// about a change or periodically if WithReloadInterval is used.
+// Use Current to get the most recent config.
+func Watch[T any](ctx context.Context, factory configFactory[T], optsSetters ...LoadOpt) (*Reloader[T], error) {
+	r, err := NewReloader(factory, optsSetters...)
+	if err != nil {
+		return nil, err
+	}
+	go r.watch(ctx)
+	return r, nil
+}
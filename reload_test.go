@@ -0,0 +1,260 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/gocombo/config/val"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockReloadableSource struct {
+	mu      sync.Mutex
+	values  map[string]val.Raw
+	loadErr error
+}
+
+func (m *mockReloadableSource) set(values map[string]val.Raw, loadErr error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values = values
+	m.loadErr = loadErr
+}
+
+func (m *mockReloadableSource) loadOpt() LoadOpt {
+	return func(opts LoadOpts) {
+		opts.AddSourceLoader(func() (Source, error) {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			if m.loadErr != nil {
+				return nil, m.loadErr
+			}
+			return &mockKeyValueSource{values: m.values}, nil
+		})
+	}
+}
+
+type mockWatchableSource struct {
+	mockKeyValueSource
+	notify  chan func()
+	stopped chan struct{}
+}
+
+func (m *mockWatchableSource) Watch(ctx context.Context, notify func()) {
+	select {
+	case m.notify <- notify:
+	case <-ctx.Done():
+		return
+	}
+	<-ctx.Done()
+	if m.stopped != nil {
+		close(m.stopped)
+	}
+}
+
+func TestReloader(t *testing.T) {
+	type config struct {
+		val1 string
+	}
+	testConfigFactory := func(p val.Provider) *config {
+		return &config{
+			val1: val.Define[string](p, "val1"),
+		}
+	}
+	randomValues := func() map[string]val.Raw {
+		return map[string]val.Raw{
+			"val1": {Key: "val1", Val: gofakeit.Generate("val1-{word}")},
+		}
+	}
+
+	t.Run("load initial config", func(t *testing.T) {
+		src := &mockReloadableSource{}
+		values := randomValues()
+		src.set(values, nil)
+		r, err := NewReloader(testConfigFactory, src.loadOpt())
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, &config{val1: values["val1"].Val.(string)}, r.Current())
+	})
+	t.Run("fail if initial config failed to build", func(t *testing.T) {
+		src := &mockReloadableSource{}
+		src.set(map[string]val.Raw{}, nil)
+		_, err := NewReloader(testConfigFactory, src.loadOpt())
+		assert.Error(t, err)
+	})
+	t.Run("swap config on reload", func(t *testing.T) {
+		src := &mockReloadableSource{}
+		src.set(randomValues(), nil)
+		r, err := NewReloader(testConfigFactory, src.loadOpt())
+		if !assert.NoError(t, err) {
+			return
+		}
+		newValues := randomValues()
+		src.set(newValues, nil)
+		if !assert.NoError(t, r.Reload()) {
+			return
+		}
+		assert.Equal(t, &config{val1: newValues["val1"].Val.(string)}, r.Current())
+	})
+	t.Run("keep last good config if source failed to load", func(t *testing.T) {
+		src := &mockReloadableSource{}
+		src.set(randomValues(), nil)
+		r, err := NewReloader(testConfigFactory, src.loadOpt())
+		if !assert.NoError(t, err) {
+			return
+		}
+		want := r.Current()
+		wantErr := errors.New(gofakeit.Sentence(3))
+		src.set(randomValues(), wantErr)
+		assert.Equal(t, wantErr, r.Reload())
+		assert.Same(t, want, r.Current())
+	})
+	t.Run("keep last good config if failed to build", func(t *testing.T) {
+		src := &mockReloadableSource{}
+		src.set(randomValues(), nil)
+		r, err := NewReloader(testConfigFactory, src.loadOpt())
+		if !assert.NoError(t, err) {
+			return
+		}
+		want := r.Current()
+		src.set(map[string]val.Raw{}, nil)
+		gotErr := r.Reload()
+		var buildErr ErrBuildFailed
+		assert.ErrorAs(t, gotErr, &buildErr)
+		assert.Same(t, want, r.Current())
+	})
+	t.Run("Watch", func(t *testing.T) {
+		t.Run("reload periodically", func(t *testing.T) {
+			src := &mockReloadableSource{}
+			src.set(randomValues(), nil)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			r, err := Watch(ctx, testConfigFactory, src.loadOpt(), WithReloadInterval(time.Millisecond))
+			if !assert.NoError(t, err) {
+				return
+			}
+			newValues := randomValues()
+			src.set(newValues, nil)
+			want := &config{val1: newValues["val1"].Val.(string)}
+			assert.Eventually(t, func() bool {
+				return *r.Current() == *want
+			}, time.Second, time.Millisecond)
+		})
+		t.Run("reload when watchable source notifies", func(t *testing.T) {
+			src := &mockReloadableSource{}
+			src.set(randomValues(), nil)
+			watchable := &mockWatchableSource{notify: make(chan func(), 1)}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			r, err := Watch(ctx, testConfigFactory,
+				func(opts LoadOpts) {
+					opts.AddSourceLoader(func() (Source, error) {
+						return watchable, nil
+					})
+				},
+				src.loadOpt(),
+			)
+			if !assert.NoError(t, err) {
+				return
+			}
+			var notify func()
+			select {
+			case notify = <-watchable.notify:
+			case <-time.After(time.Second):
+				assert.Fail(t, "source is not watched")
+				return
+			}
+			newValues := randomValues()
+			src.set(newValues, nil)
+			notify()
+			want := &config{val1: newValues["val1"].Val.(string)}
+			assert.Eventually(t, func() bool {
+				return *r.Current() == *want
+			}, time.Second, time.Millisecond)
+		})
+		t.Run("watch sources of reloaded config", func(t *testing.T) {
+			src := &mockReloadableSource{}
+			src.set(randomValues(), nil)
+			watchables := make(chan *mockWatchableSource, 2)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			_, err := Watch(ctx, testConfigFactory,
+				func(opts LoadOpts) {
+					opts.AddSourceLoader(func() (Source, error) {
+						watchable := &mockWatchableSource{
+							notify:  make(chan func(), 1),
+							stopped: make(chan struct{}),
+						}
+						watchables <- watchable
+						return watchable, nil
+					})
+				},
+				src.loadOpt(),
+			)
+			if !assert.NoError(t, err) {
+				return
+			}
+			initial := <-watchables
+			var notify func()
+			select {
+			case notify = <-initial.notify:
+			case <-time.After(time.Second):
+				assert.Fail(t, "initial source is not watched")
+				return
+			}
+			notify()
+			var reloaded *mockWatchableSource
+			select {
+			case reloaded = <-watchables:
+			case <-time.After(time.Second):
+				assert.Fail(t, "config is not reloaded")
+				return
+			}
+			select {
+			case <-reloaded.notify:
+			case <-time.After(time.Second):
+				assert.Fail(t, "reloaded source is not watched")
+			}
+			select {
+			case <-initial.stopped:
+			case <-time.After(time.Second):
+				assert.Fail(t, "initial source is still watched")
+			}
+		})
+		t.Run("report reload errors", func(t *testing.T) {
+			src := &mockReloadableSource{}
+			src.set(randomValues(), nil)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			wantErr := errors.New(gofakeit.Sentence(3))
+			gotErrs := make(chan error, 1)
+			r, err := Watch(ctx, testConfigFactory,
+				src.loadOpt(),
+				WithReloadInterval(time.Millisecond),
+				OnReloadError(func(err error) {
+					select {
+					case gotErrs <- err:
+					default:
+					}
+				}),
+			)
+			if !assert.NoError(t, err) {
+				return
+			}
+			want := r.Current()
+			src.set(randomValues(), wantErr)
+			select {
+			case gotErr := <-gotErrs:
+				assert.Equal(t, wantErr, gotErr)
+			case <-time.After(time.Second):
+				assert.Fail(t, "reload error not reported")
+			}
+			assert.Same(t, want, r.Current())
+		})
+	})
+}
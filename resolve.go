@@ -0,0 +1,178 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gocombo/config/val"
+)
+
+// Resolver resolves a reference found in a string value of a source,
+// e.g vault://db/creds#password. Resolve receives the whole reference
+// including the scheme and returns the actual value.
+type Resolver interface {
+	Resolve(ref string) (interface{}, error)
+}
+
+// ResolverFunc allows using ordinary functions as a Resolver
+type ResolverFunc func(ref string) (interface{}, error)
+
+func (f ResolverFunc) Resolve(ref string) (interface{}, error) {
+	return f(ref)
+}
+
+// ErrResolveFailed is notified if a reference in the value could not be resolved
+type ErrResolveFailed struct {
+	Key       string
+	Reference string
+	Err       error
+}
+
+func (e ErrResolveFailed) Error() string {
+	return fmt.Sprintf("failed to resolve %s from %s: %v", e.Key, e.Reference, e.Err)
+}
+
+func (e ErrResolveFailed) Unwrap() error {
+	return e.Err
+}
+
+// WithResolver registers a resolver for string values starting with the
+// scheme followed by a colon, e.g "vault" for vault://db/creds#password.
+// Values are resolved when requested, before they are converted.
+func WithResolver(scheme string, r Resolver) LoadOpt {
+	return withLoadOpts(func(opts *loadOpts) {
+		if opts.resolvers == nil {
+			opts.resolvers = map[string]Resolver{}
+		}
+		opts.resolvers[scheme] = r
+	})
+}
+
+// WithDefaultResolvers registers built-in resolvers:
+//   - env://NAME is replaced with a value of the environment variable
+//   - file:///path is replaced with the file content without trailing line breaks
+//   - base64:data is replaced with the decoded data
+func WithDefaultResolvers() LoadOpt {
+	return func(opts LoadOpts) {
+		WithResolver("env", ResolverFunc(resolveEnv))(opts)
+		WithResolver("file", ResolverFunc(resolveFile))(opts)
+		WithResolver("base64", ResolverFunc(resolveBase64))(opts)
+	}
+}
+
+func resolveEnv(ref string) (interface{}, error) {
+	name := strings.TrimPrefix(ref, "env://")
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+func resolveFile(ref string) (interface{}, error) {
+	data, err := os.ReadFile(strings.TrimPrefix(ref, "file://"))
+	if err != nil {
+		return nil, err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func resolveBase64(ref string) (interface{}, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(ref, "base64:"))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// isSchemeChar reports if c is allowed in URI scheme, the first one must be a letter
+func isSchemeChar(c rune, first bool) bool {
+	isLetter := ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+	if first {
+		return isLetter
+	}
+	return isLetter || ('0' <= c && c <= '9') || c == '+' || c == '-' || c == '.'
+}
+
+// referenceScheme returns URI scheme (RFC 3986) of the value or empty string
+func referenceScheme(value string) string {
+	end := strings.IndexByte(value, ':')
+	if end <= 0 {
+		return ""
+	}
+	for i, c := range value[:end] {
+		if !isSchemeChar(c, i == 0) {
+			return ""
+		}
+	}
+	return value[:end]
+}
+
+// mapStrings returns a copy of the value with strings nested in maps and
+// slices replaced with results of fn, so values of sources are not modified.
+// fn receives "/" separated key of the string.
+func mapStrings(key string, value interface{}, fn func(key, s string) (interface{}, error)) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return fn(key, v)
+	case map[string]interface{}:
+		itemKeys := make([]string, 0, len(v))
+		for itemKey := range v {
+			itemKeys = append(itemKeys, itemKey)
+		}
+		// Sorted so the same error is reported if many items fail
+		sort.Strings(itemKeys)
+		result := make(map[string]interface{}, len(v))
+		for _, itemKey := range itemKeys {
+			item, err := mapStrings(key+"/"+itemKey, v[itemKey], fn)
+			if err != nil {
+				return nil, err
+			}
+			result[itemKey] = item
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			mapped, err := mapStrings(key+"/"+strconv.Itoa(i), item, fn)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = mapped
+		}
+		return result, nil
+	default:
+		return value, nil
+	}
+}
+
+// resolve replaces references in the raw value using registered resolvers.
+// References nested in objects and arrays are resolved too.
+// Failures are notified and the value is considered missing.
+func (p *valuesProvider) resolve(key string, raw val.Raw) (val.Raw, bool) {
+	if len(p.resolvers) == 0 {
+		return raw, true
+	}
+	resolved, err := mapStrings(key, raw.Val, func(refKey, ref string) (interface{}, error) {
+		r, ok := p.resolvers[referenceScheme(ref)]
+		if !ok {
+			return ref, nil
+		}
+		resolved, err := r.Resolve(ref)
+		if err != nil {
+			return nil, ErrResolveFailed{Key: refKey, Reference: ref, Err: err}
+		}
+		return resolved, nil
+	})
+	if err != nil {
+		p.failedKeys[key] = true
+		p.NotifyError(key, err)
+		return val.Raw{}, false
+	}
+	raw.Val = resolved
+	return raw, true
+}
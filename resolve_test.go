@@ -0,0 +1,222 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/gocombo/config/val"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolvers(t *testing.T) {
+	type config struct {
+		val1 string
+		val2 int
+	}
+	testConfigFactory := func(p val.Provider) *config {
+		return &config{
+			val1: val.Define[string](p, "val1"),
+			val2: val.Define[int](p, "val2", val.Optional()),
+		}
+	}
+	withValues := func(values map[string]interface{}) LoadOpt {
+		src := &mockKeyValueSource{values: map[string]val.Raw{}}
+		for key, v := range values {
+			src.values[key] = val.Raw{Key: key, Val: v}
+		}
+		return func(opts LoadOpts) {
+			opts.AddSourceLoader(func() (Source, error) {
+				return src, nil
+			})
+		}
+	}
+
+	t.Run("resolve with registered resolver", func(t *testing.T) {
+		want := gofakeit.Password(true, true, true, true, false, 16)
+		var gotRef string
+		cfg, err := Load(testConfigFactory,
+			withValues(map[string]interface{}{"val1": "vault://db/creds#password", "val2": "mock:42"}),
+			WithResolver("vault", ResolverFunc(func(ref string) (interface{}, error) {
+				gotRef = ref
+				return want, nil
+			})),
+			WithResolver("mock", ResolverFunc(func(ref string) (interface{}, error) {
+				return 42, nil
+			})),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, &config{val1: want, val2: 42}, cfg)
+		assert.Equal(t, "vault://db/creds#password", gotRef)
+	})
+	t.Run("keep values without registered scheme", func(t *testing.T) {
+		values := map[string]interface{}{"val1": "https://example.com", "val2": 10}
+		cfg, err := Load(testConfigFactory,
+			withValues(values),
+			WithDefaultResolvers(),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, &config{val1: "https://example.com", val2: 10}, cfg)
+	})
+	t.Run("report resolve errors per key", func(t *testing.T) {
+		wantErr := errors.New(gofakeit.Sentence(3))
+		_, err := Load(testConfigFactory,
+			withValues(map[string]interface{}{"val1": "vault://db/creds#password", "val2": "vault://db/creds#port"}),
+			WithResolver("vault", ResolverFunc(func(ref string) (interface{}, error) {
+				return nil, wantErr
+			})),
+		)
+		var buildErr ErrBuildFailed
+		if !assert.ErrorAs(t, err, &buildErr) {
+			return
+		}
+		assert.Equal(t, []error{
+			ErrResolveFailed{Key: "val1", Reference: "vault://db/creds#password", Err: wantErr},
+			ErrResolveFailed{Key: "val2", Reference: "vault://db/creds#port", Err: wantErr},
+		}, buildErr.Errors)
+		assert.ErrorIs(t, err, wantErr)
+	})
+	t.Run("resolve nested references", func(t *testing.T) {
+		type db struct {
+			Host     string   `json:"host"`
+			Password string   `json:"password"`
+			Replicas []string `json:"replicas"`
+		}
+		wantPassword := gofakeit.Password(true, true, true, true, false, 16)
+		wantReplica := gofakeit.DomainName()
+		values := map[string]interface{}{
+			"db": map[string]interface{}{
+				"host":     "localhost",
+				"password": "mock://password",
+				"replicas": []interface{}{"mock://replica"},
+			},
+		}
+		got, err := Load(func(p val.Provider) *db {
+			v := val.Define[db](p, "db")
+			return &v
+		},
+			withValues(values),
+			WithResolver("mock", ResolverFunc(func(ref string) (interface{}, error) {
+				if ref == "mock://password" {
+					return wantPassword, nil
+				}
+				return wantReplica, nil
+			})),
+		)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, &db{Host: "localhost", Password: wantPassword, Replicas: []string{wantReplica}}, got)
+		assert.Equal(t, "mock://password", values["db"].(map[string]interface{})["password"], "source value modified")
+	})
+	t.Run("report nested resolve errors", func(t *testing.T) {
+		wantErr := errors.New(gofakeit.Sentence(3))
+		_, err := Load(func(p val.Provider) *map[string]string {
+			v := val.Define[map[string]string](p, "db")
+			return &v
+		},
+			withValues(map[string]interface{}{
+				"db": map[string]interface{}{"password": "vault://db/creds#password"},
+			}),
+			WithResolver("vault", ResolverFunc(func(ref string) (interface{}, error) {
+				return nil, wantErr
+			})),
+		)
+		var buildErr ErrBuildFailed
+		if !assert.ErrorAs(t, err, &buildErr) {
+			return
+		}
+		assert.Equal(t, []error{
+			ErrResolveFailed{Key: "db/password", Reference: "vault://db/creds#password", Err: wantErr},
+		}, buildErr.Errors)
+	})
+	t.Run("default resolvers", func(t *testing.T) {
+		t.Run("env", func(t *testing.T) {
+			env := gofakeit.Generate("TEST_ENV_{word}")
+			want := gofakeit.SentenceSimple()
+			t.Setenv(env, want)
+			cfg, err := Load(testConfigFactory,
+				withValues(map[string]interface{}{"val1": "env://" + env}),
+				WithDefaultResolvers(),
+			)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, want, cfg.val1)
+		})
+		t.Run("missing env", func(t *testing.T) {
+			env := gofakeit.Generate("TEST_ENV_{word}")
+			_, err := Load(testConfigFactory,
+				withValues(map[string]interface{}{"val1": "env://" + env}),
+				WithDefaultResolvers(),
+			)
+			assert.EqualError(t, err, "failed building config: failed to resolve val1 from env://"+env+
+				": environment variable "+env+" is not set")
+		})
+		t.Run("file", func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), gofakeit.Word())
+			want := gofakeit.SentenceSimple()
+			if !assert.NoError(t, os.WriteFile(filePath, []byte(want+"\n"), 0o600)) {
+				return
+			}
+			cfg, err := Load(testConfigFactory,
+				withValues(map[string]interface{}{"val1": "file://" + filePath}),
+				WithDefaultResolvers(),
+			)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, want, cfg.val1)
+		})
+		t.Run("missing file", func(t *testing.T) {
+			_, err := Load(testConfigFactory,
+				withValues(map[string]interface{}{"val1": "file://" + filepath.Join(t.TempDir(), gofakeit.Word())}),
+				WithDefaultResolvers(),
+			)
+			assert.ErrorIs(t, err, os.ErrNotExist)
+		})
+		t.Run("base64", func(t *testing.T) {
+			want := gofakeit.SentenceSimple()
+			cfg, err := Load(testConfigFactory,
+				withValues(map[string]interface{}{
+					"val1": "base64:" + base64.StdEncoding.EncodeToString([]byte(want)),
+					"val2": "base64:" + base64.StdEncoding.EncodeToString([]byte("42")),
+				}),
+				WithDefaultResolvers(),
+			)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, &config{val1: want, val2: 42}, cfg)
+		})
+		t.Run("invalid base64", func(t *testing.T) {
+			_, err := Load(testConfigFactory,
+				withValues(map[string]interface{}{"val1": "base64:not base64!"}),
+				WithDefaultResolvers(),
+			)
+			var base64Err base64.CorruptInputError
+			assert.ErrorAs(t, err, &base64Err)
+		})
+	})
+}
+
+func TestReferenceScheme(t *testing.T) {
+	tests := map[string]string{
+		"vault://db/creds#password": "vault",
+		"base64:aGVsbG8=":           "base64",
+		"git+ssh://host":            "git+ssh",
+		"plain value":               "",
+		":no-scheme":                "",
+		"1abc:value":                "",
+	}
+	for value, want := range tests {
+		assert.Equal(t, want, referenceScheme(value), value)
+	}
+}
@@ -0,0 +1,175 @@
+package tomlsrc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/gocombo/config"
+	"github.com/gocombo/config/internal/keypath"
+	"github.com/gocombo/config/internal/poll"
+	"github.com/gocombo/config/val"
+)
+
+type loadOpts struct {
+	name              string
+	baseDir           string
+	ignoreMissingFile bool
+	pollInterval      time.Duration
+	openFile          func(fileName string) (file io.ReadCloser, err error)
+}
+
+func defaultLoadOpts() loadOpts {
+	return loadOpts{
+		baseDir:           "",
+		ignoreMissingFile: false,
+		pollInterval:      poll.DefaultInterval,
+		openFile: func(fileName string) (file io.ReadCloser, err error) {
+			return os.Open(fileName)
+		},
+	}
+}
+
+func (o *loadOpts) set(optSetter []LoadOpt) {
+	for _, opt := range optSetter {
+		opt(o)
+	}
+}
+
+func (o *loadOpts) sourceName(filePath string) string {
+	if o.name != "" {
+		return o.name
+	}
+	return filePath
+}
+
+type LoadOpt func(opts *loadOpts)
+
+// WithName sets a name of the source that is used in errors
+// and value origin. Path of the file is used by default.
+func WithName(name string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.name = name
+	}
+}
+
+func WithBaseDir(baseDir string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.baseDir = baseDir
+	}
+}
+
+func IgnoreMissingFile() LoadOpt {
+	return func(opts *loadOpts) {
+		opts.ignoreMissingFile = true
+	}
+}
+
+// WithPollInterval defines how often the file is checked for changes
+// when config is watched
+func WithPollInterval(interval time.Duration) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.pollInterval = interval
+	}
+}
+
+// normalize converts arrays of tables to []interface{}
+// so they are handled same way as arrays of other values
+func normalize(v interface{}) interface{} {
+	switch actualVal := v.(type) {
+	case map[string]interface{}:
+		for key, item := range actualVal {
+			actualVal[key] = normalize(item)
+		}
+		return actualVal
+	case []map[string]interface{}:
+		result := make([]interface{}, len(actualVal))
+		for i, item := range actualVal {
+			result[i] = normalize(item)
+		}
+		return result
+	case []interface{}:
+		for i, item := range actualVal {
+			actualVal[i] = normalize(item)
+		}
+		return actualVal
+	default:
+		return v
+	}
+}
+
+type source struct {
+	name         string
+	filePath     string
+	pollInterval time.Duration
+	rawValues    map[string]interface{}
+}
+
+func (src *source) Name() string {
+	return src.name
+}
+
+// Watch polls the file for changes
+func (src *source) Watch(ctx context.Context, notify func()) {
+	poll.Files(ctx, src.pollInterval, notify, src.filePath)
+}
+
+func (src *source) GetValue(key string) (val.Raw, bool) {
+	if v := keypath.Lookup(key, src.rawValues); v != nil {
+		return val.Raw{
+			Key: key,
+			Val: v,
+			Origin: val.Origin{
+				Source:  src.name,
+				File:    src.filePath,
+				Pointer: keypath.JSONPointer(key),
+			},
+		}, true
+	}
+	return val.Raw{}, false
+}
+
+// Load adds TOML file source. Tables are available as "/" separated keys.
+// Datetimes are passed as time.Time values and durations
+// are expected as strings, e.g "10s".
+func Load(fileName string, optSetter ...LoadOpt) config.LoadOpt {
+	return func(opts config.LoadOpts) {
+		srcOpts := defaultLoadOpts()
+		srcOpts.set(optSetter)
+		name := srcOpts.sourceName(path.Join(srcOpts.baseDir, fileName))
+		opts.AddSourceLoader(config.NamedSourceLoader(name, func() (config.Source, error) {
+			return load(fileName, optSetter...)
+		}))
+	}
+}
+
+func load(fileName string, optSetter ...LoadOpt) (config.Source, error) {
+	opts := defaultLoadOpts()
+	opts.set(optSetter)
+
+	filePath := path.Join(opts.baseDir, fileName)
+	name := opts.sourceName(filePath)
+	file, err := opts.openFile(filePath)
+	if err != nil {
+		if opts.ignoreMissingFile && os.IsNotExist(err) {
+			return &source{name: name, filePath: filePath, pollInterval: opts.pollInterval}, nil
+		}
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	src := source{
+		name:         name,
+		filePath:     filePath,
+		pollInterval: opts.pollInterval,
+		rawValues:    map[string]interface{}{},
+	}
+	if _, err := toml.NewDecoder(file).Decode(&src.rawValues); err != nil {
+		return nil, fmt.Errorf("failed to decode toml: %w", err)
+	}
+	normalize(src.rawValues)
+	return &src, nil
+}
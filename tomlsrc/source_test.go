@@ -0,0 +1,238 @@
+package tomlsrc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/gocombo/config"
+	"github.com/gocombo/config/val"
+	"github.com/stretchr/testify/assert"
+)
+
+type closableBuffer bytes.Buffer
+
+func (b *closableBuffer) Read(p []byte) (n int, err error) {
+	return (*bytes.Buffer)(b).Read(p)
+}
+
+func (b *closableBuffer) Close() error {
+	return nil
+}
+
+type mockLoadOpts struct {
+	sourceLoaders []config.SourceLoader
+}
+
+func (m *mockLoadOpts) AddSourceLoader(loader config.SourceLoader) {
+	m.sourceLoaders = append(m.sourceLoaders, loader)
+}
+
+func withMockData(data string) LoadOpt {
+	return func(s *loadOpts) {
+		s.openFile = func(fileName string) (file io.ReadCloser, err error) {
+			return (*closableBuffer)(bytes.NewBufferString(data)), nil
+		}
+	}
+}
+
+func TestTomlSource(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		loadFromOpts := func(fileName string, opts ...LoadOpt) (config.Source, error) {
+			mockOpts := &mockLoadOpts{}
+			loadOpt := Load(fileName, opts...)
+			loadOpt(mockOpts)
+			if len(mockOpts.sourceLoaders) < 1 {
+				return nil, fmt.Errorf("no source loader added to opts")
+			}
+			return mockOpts.sourceLoaders[0]()
+		}
+		t.Run("fail if no such file", func(t *testing.T) {
+			fileName := gofakeit.Generate("{name}.toml")
+			_, err := loadFromOpts(fileName)
+			assert.ErrorIs(t, err, os.ErrNotExist)
+			assert.Contains(t, err.Error(), fmt.Sprintf("failed to load %s: ", fileName))
+		})
+		t.Run("name source by file path", func(t *testing.T) {
+			fileName := gofakeit.Generate("{name}.toml")
+			source, err := loadFromOpts(fileName, withMockData(""))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, fileName, source.(config.Named).Name())
+		})
+		t.Run("attribute errors to named source", func(t *testing.T) {
+			name := gofakeit.Word()
+			_, err := loadFromOpts(gofakeit.Generate("{name}.toml"), WithName(name))
+			assert.ErrorIs(t, err, os.ErrNotExist)
+			assert.Contains(t, err.Error(), fmt.Sprintf("failed to load %s: ", name))
+		})
+		t.Run("optionally not fail if no such file", func(t *testing.T) {
+			source, err := loadFromOpts(gofakeit.Generate("{name}.toml"), IgnoreMissingFile())
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.NotNil(t, source)
+		})
+		t.Run("fail if not a TOML", func(t *testing.T) {
+			_, err := loadFromOpts(gofakeit.Generate("{name}.toml"), withMockData("not a toml"))
+			assert.ErrorContains(t, err, "failed to decode toml")
+		})
+		t.Run("load from base dir", func(t *testing.T) {
+			wantFileName := gofakeit.Generate("{name}.toml")
+			wantDir := gofakeit.Generate("/{name}/{name}")
+			var gotFilePath string
+			_, err := loadFromOpts(
+				wantFileName,
+				WithBaseDir(wantDir),
+				func(opts *loadOpts) {
+					opts.openFile = func(fileName string) (file io.ReadCloser, err error) {
+						gotFilePath = fileName
+						return (*closableBuffer)(bytes.NewBufferString("")), nil
+					}
+				},
+			)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, path.Join(wantDir, wantFileName), gotFilePath)
+		})
+	})
+
+	t.Run("GetValue", func(t *testing.T) {
+		strVal := gofakeit.Word()
+		nestedVal := gofakeit.Word()
+		port := gofakeit.Number(1000, 9000)
+		data := fmt.Sprintf(`
+str_val = "%[1]s"
+created = 2023-05-01T10:30:00Z
+
+[nested]
+str_val = "%[2]s"
+
+[server.http]
+port = %[3]d
+
+[[servers]]
+host = "a.example.com"
+
+[[servers]]
+host = "b.example.com"
+`, strVal, nestedVal, port)
+		source, err := load(gofakeit.Generate("{name}.toml"), withMockData(data))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assertVal := func(key string, wantVal interface{}) {
+			gotVal, ok := source.GetValue(key)
+			if !assert.True(t, ok, "Value %s not found", key) {
+				return
+			}
+			assert.Equal(t, wantVal, gotVal.Val)
+		}
+		assertVal("str_val", strVal)
+		assertVal("nested/str_val", nestedVal)
+		assertVal("server/http/port", int64(port))
+		assertVal("created", time.Date(2023, 5, 1, 10, 30, 0, 0, time.UTC))
+		assertVal("servers", []interface{}{
+			map[string]interface{}{"host": "a.example.com"},
+			map[string]interface{}{"host": "b.example.com"},
+		})
+
+		_, ok := source.GetValue("not/existing/key")
+		assert.False(t, ok, "Value not/existing/key found")
+	})
+
+	t.Run("define values", func(t *testing.T) {
+		type server struct {
+			Host string `json:"host"`
+			Port int    `json:"port"`
+		}
+		type testConfig struct {
+			port    int
+			created time.Time
+			timeout time.Duration
+			servers []server
+		}
+		tmpDir := t.TempDir()
+		fileName := gofakeit.Generate("{name}.toml")
+		data := `
+created = 2023-05-01T10:30:00+02:00
+timeout = "10s"
+
+[server]
+port = 8080
+
+[[servers]]
+host = "a.example.com"
+port = 80
+
+[[servers]]
+host = "b.example.com"
+port = 81
+`
+		if err := os.WriteFile(filepath.Join(tmpDir, fileName), []byte(data), 0o644); !assert.NoError(t, err) {
+			return
+		}
+		got, err := config.Load(func(p val.Provider) *testConfig {
+			return &testConfig{
+				port:    val.Define[int](p, "server/port"),
+				created: val.Define[time.Time](p, "created"),
+				timeout: val.Define[time.Duration](p, "timeout"),
+				servers: val.Define[[]server](p, "servers"),
+			}
+		}, Load(fileName, WithBaseDir(tmpDir)))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, 8080, got.port)
+		assert.True(t, time.Date(2023, 5, 1, 8, 30, 0, 0, time.UTC).Equal(got.created))
+		assert.Equal(t, 10*time.Second, got.timeout)
+		assert.Equal(t, []server{{"a.example.com", 80}, {"b.example.com", 81}}, got.servers)
+	})
+
+	t.Run("Watch", func(t *testing.T) {
+		t.Run("notify when file changes", func(t *testing.T) {
+			tmpDir := t.TempDir()
+			fileName := gofakeit.Generate("{name}.toml")
+			writeValue := func(value string) {
+				data := []byte(fmt.Sprintf("key = %q", value))
+				if err := os.WriteFile(filepath.Join(tmpDir, fileName), data, 0o644); !assert.NoError(t, err) {
+					t.FailNow()
+				}
+			}
+			writeValue(gofakeit.Word())
+			source, err := load(fileName, WithBaseDir(tmpDir), WithPollInterval(time.Millisecond))
+			if !assert.NoError(t, err) {
+				return
+			}
+			watchable, ok := source.(config.Watchable)
+			if !assert.True(t, ok, "source is not watchable") {
+				return
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			notified := make(chan struct{}, 1)
+			go watchable.Watch(ctx, func() {
+				select {
+				case notified <- struct{}{}:
+				default:
+				}
+			})
+			time.Sleep(20 * time.Millisecond)
+			writeValue(gofakeit.Word() + "-changed")
+			select {
+			case <-notified:
+			case <-time.After(time.Second):
+				assert.Fail(t, "change not notified")
+			}
+		})
+	})
+}
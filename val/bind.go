@@ -0,0 +1,127 @@
+package val
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// TagName is the struct tag used by Bind
+const TagName = "config"
+
+func parseTag(tag string) (key string, opts defineOptions, err error) {
+	parts := strings.Split(tag, ",")
+	key = parts[0]
+	for _, opt := range parts[1:] {
+		switch opt {
+		case "optional":
+			opts.optional = true
+		default:
+			return key, opts, fmt.Errorf("unknown %s tag option %q", TagName, opt)
+		}
+	}
+	return key, opts, nil
+}
+
+// bindStruct defines tagged fields of the target. Types that are being
+// bound are tracked in binding, so self-referencing types are not
+// recursed into infinitely
+func bindStruct(l Provider, target reflect.Value, binding map[reflect.Type]bool) {
+	targetType := target.Type()
+	binding[targetType] = true
+	defer delete(binding, targetType)
+	for i := 0; i < targetType.NumField(); i++ {
+		field := targetType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldVal := target.Field(i)
+		tag, hasTag := field.Tag.Lookup(TagName)
+		if !hasTag {
+			bindNested(l, fieldVal, binding)
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+		key, opts, err := parseTag(tag)
+		if err != nil {
+			l.NotifyError(key, fmt.Errorf("field %s.%s: %w", targetType.Name(), field.Name, err))
+			continue
+		}
+		define(l, key, fieldVal, opts)
+	}
+}
+
+// bindNested binds fields of untagged struct or struct pointer fields.
+// Pointers are allocated only if the struct has tagged fields, so
+// fields like *http.Client are left as is.
+func bindNested(l Provider, fieldVal reflect.Value, binding map[reflect.Type]bool) {
+	switch {
+	case fieldVal.Kind() == reflect.Struct:
+		if !binding[fieldVal.Type()] {
+			bindStruct(l, fieldVal, binding)
+		}
+	case fieldVal.Kind() == reflect.Pointer && fieldVal.Type().Elem().Kind() == reflect.Struct:
+		elemType := fieldVal.Type().Elem()
+		if binding[elemType] || !hasTaggedFields(elemType, map[reflect.Type]bool{}) {
+			return
+		}
+		if fieldVal.IsNil() {
+			fieldVal.Set(reflect.New(elemType))
+		}
+		bindStruct(l, fieldVal.Elem(), binding)
+	}
+}
+
+// hasTaggedFields reports if the struct type or any of its
+// untagged nested structs have fields with `config` tag
+func hasTaggedFields(structType reflect.Type, visited map[reflect.Type]bool) bool {
+	if visited[structType] {
+		return false
+	}
+	visited[structType] = true
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if tag, hasTag := field.Tag.Lookup(TagName); hasTag {
+			if tag != "-" {
+				return true
+			}
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct && hasTaggedFields(fieldType, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// Bind creates a new T and defines its fields same way as Define does
+// using keys from `config` struct tags, for example:
+//
+//	type Server struct {
+//		Port        int           `config:"server/port"`
+//		IdleTimeout time.Duration `config:"server/idleTimeout,optional"`
+//	}
+//
+// Fields without the tag that are structs or struct pointers
+// are bound recursively, other fields without the tag are left as is.
+// Nil struct pointers are allocated only if the struct has tagged fields.
+// Use `config:"-"` to skip a struct field.
+func Bind[T any](l Provider) *T {
+	result := new(T)
+	resultVal := reflect.ValueOf(result).Elem()
+	if resultVal.Kind() != reflect.Struct {
+		l.NotifyError("", fmt.Errorf("can not bind %T, struct expected", *result))
+		return result
+	}
+	bindStruct(l, resultVal, map[reflect.Type]bool{})
+	return result
+}
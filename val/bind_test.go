@@ -0,0 +1,123 @@
+package val
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBind(t *testing.T) {
+	type server struct {
+		Port        int           `config:"server/port"`
+		IdleTimeout time.Duration `config:"server/idleTimeout,optional"`
+	}
+	type hello struct {
+		Message string `config:"hello/message"`
+	}
+	type nested struct {
+		Hello hello
+	}
+	type bindConfig struct {
+		Times    int      `config:"sayHelloTimes"`
+		Tags     []string `config:"tags"`
+		Server   *server
+		Nested   nested
+		Skipped  string `config:"-"`
+		Untagged string
+		internal string
+	}
+
+	t.Run("bind tagged fields", func(t *testing.T) {
+		wantTimes := gofakeit.Number(1, 100)
+		wantPort := gofakeit.Number(1000, 9000)
+		wantMessage := gofakeit.SentenceSimple()
+		wantTags := []string{gofakeit.Word(), gofakeit.Word()}
+		loader := &mockLoader{
+			rawByPath: map[string]Raw{
+				"sayHelloTimes": {Val: float64(wantTimes)},
+				"tags":          {Val: []interface{}{wantTags[0], wantTags[1]}},
+				"server/port":   {Val: fmt.Sprint(wantPort)},
+				"hello/message": {Val: wantMessage},
+				"-":             {Val: gofakeit.Word()},
+			},
+			errorsByPath: map[string]error{},
+		}
+		got := Bind[bindConfig](loader)
+		assert.Empty(t, loader.errorsByPath)
+		assert.Equal(t, &bindConfig{
+			Times:  wantTimes,
+			Tags:   wantTags,
+			Server: &server{Port: wantPort},
+			Nested: nested{Hello: hello{Message: wantMessage}},
+		}, got)
+	})
+	t.Run("notify errors", func(t *testing.T) {
+		loader := &mockLoader{
+			rawByPath: map[string]Raw{
+				"sayHelloTimes": {Val: gofakeit.Word()},
+				"server/port":   {Val: gofakeit.Number(1000, 9000)},
+			},
+			errorsByPath: map[string]error{},
+		}
+		Bind[bindConfig](loader)
+		assert.Len(t, loader.errorsByPath, 3)
+		assert.ErrorAs(t, loader.errorsByPath["sayHelloTimes"], &ErrConversion{})
+		assert.Equal(t, ErrMissingValue{Key: "tags"}, loader.errorsByPath["tags"])
+		assert.Equal(t, ErrMissingValue{Key: "hello/message"}, loader.errorsByPath["hello/message"])
+	})
+	t.Run("skip struct pointers without tagged fields", func(t *testing.T) {
+		type client struct {
+			Timeout time.Duration
+		}
+		type node struct {
+			Name string `config:"name"`
+			Next *node
+		}
+		type clientConfig struct {
+			Client *client
+			Root   *node
+		}
+		wantName := gofakeit.Word()
+		loader := &mockLoader{
+			rawByPath:    map[string]Raw{"name": {Val: wantName}},
+			errorsByPath: map[string]error{},
+		}
+		got := Bind[clientConfig](loader)
+		assert.Empty(t, loader.errorsByPath)
+		assert.Equal(t, &clientConfig{Root: &node{Name: wantName}}, got)
+	})
+	t.Run("bind self referencing types", func(t *testing.T) {
+		type node struct {
+			Next *node
+		}
+		loader := &mockLoader{
+			rawByPath:    map[string]Raw{},
+			errorsByPath: map[string]error{},
+		}
+		got := Bind[node](loader)
+		assert.Empty(t, loader.errorsByPath)
+		assert.Equal(t, &node{}, got)
+	})
+	t.Run("notify invalid tag", func(t *testing.T) {
+		type invalidConfig struct {
+			Val string `config:"val,required"`
+		}
+		loader := &mockLoader{
+			rawByPath:    map[string]Raw{"val": {Val: gofakeit.Word()}},
+			errorsByPath: map[string]error{},
+		}
+		Bind[invalidConfig](loader)
+		assert.EqualError(t, loader.errorsByPath["val"], `field invalidConfig.Val: unknown config tag option "required"`)
+	})
+	t.Run("notify not a struct", func(t *testing.T) {
+		loader := &mockLoader{
+			rawByPath:    map[string]Raw{},
+			errorsByPath: map[string]error{},
+		}
+		Bind[string](loader)
+		assert.Error(t, loader.errorsByPath[""])
+	})
+}
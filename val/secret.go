@@ -0,0 +1,119 @@
+package val
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Redacted is printed instead of the value of a Secret
+const Redacted = "[REDACTED]"
+
+// Secret holds a sensitive value that is redacted when printed with fmt,
+// marshaled to JSON or logged with slog (Go 1.21+). Use Reveal to get the actual value.
+// Secret can be defined as any other value, e.g Define[Secret[string]].
+type Secret[T any] struct {
+	// value is a pointer so it's printed as an address
+	// if the secret is a field of a struct printed with %+v
+	value *T
+}
+
+// NewSecret wraps the value into Secret
+func NewSecret[T any](value T) Secret[T] {
+	return Secret[T]{value: &value}
+}
+
+// Reveal returns the actual value
+func (s Secret[T]) Reveal() T {
+	if s.value == nil {
+		var zero T
+		return zero
+	}
+	return *s.value
+}
+
+func (s Secret[T]) String() string {
+	return Redacted
+}
+
+// Format implements fmt.Formatter so all verbs print Redacted
+func (s Secret[T]) Format(f fmt.State, verb rune) {
+	fmt.Fprint(f, Redacted)
+}
+
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(Redacted)
+}
+
+// UnmarshalJSON decodes the underlying value, so Secret
+// can be a field of a struct that is defined from an object
+func (s *Secret[T]) UnmarshalJSON(data []byte) error {
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	s.value = &value
+	return nil
+}
+
+func (s Secret[T]) revealAny() interface{} {
+	return s.Reveal()
+}
+
+// secretRevealer is implemented by Secret to use
+// a Secret as a default value of the Secret
+type secretRevealer interface {
+	revealAny() interface{}
+}
+
+// newSecretValue allocates the value and returns it as a settable reflect.Value
+func (s *Secret[T]) newSecretValue() reflect.Value {
+	s.value = new(T)
+	return reflect.ValueOf(s.value).Elem()
+}
+
+// secretHolder is implemented by *Secret to let
+// converters set the value of an underlying type
+type secretHolder interface {
+	newSecretValue() reflect.Value
+}
+
+// secretValue returns the underlying value of the target
+// if it's a Secret, otherwise false is returned
+func secretValue(target reflect.Value) (reflect.Value, bool) {
+	if !target.CanAddr() {
+		return reflect.Value{}, false
+	}
+	holder, ok := target.Addr().Interface().(secretHolder)
+	if !ok {
+		return reflect.Value{}, false
+	}
+	return holder.newSecretValue(), true
+}
+
+var secretHolderType = reflect.TypeOf((*secretHolder)(nil)).Elem()
+
+// containsSecret reports if values of the type are or hold a Secret,
+// e.g a struct with a Secret field
+func containsSecret(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+	if reflect.PointerTo(t).Implements(secretHolderType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return containsSecret(t.Elem(), visited)
+	case reflect.Map:
+		return containsSecret(t.Key(), visited) || containsSecret(t.Elem(), visited)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if containsSecret(t.Field(i).Type, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
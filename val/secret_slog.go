@@ -0,0 +1,10 @@
+//go:build go1.21
+
+package val
+
+import "log/slog"
+
+// LogValue implements slog.LogValuer so secrets are redacted in logs
+func (s Secret[T]) LogValue() slog.Value {
+	return slog.StringValue(Redacted)
+}
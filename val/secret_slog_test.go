@@ -0,0 +1,24 @@
+//go:build go1.21
+
+package val
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretLogValue(t *testing.T) {
+	type helloConfig struct {
+		Password Secret[string]
+	}
+	password := gofakeit.Password(true, true, true, false, false, 16)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Info("loaded", "password", NewSecret(password), "config", helloConfig{Password: NewSecret(password)})
+	assert.NotContains(t, buf.String(), password)
+	assert.Contains(t, buf.String(), `"password":"[REDACTED]"`)
+}
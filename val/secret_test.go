@@ -0,0 +1,184 @@
+package val
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSecretLoader struct {
+	mockLoader
+	defaults   map[string]Raw
+	secretKeys map[string]bool
+}
+
+func (l *mockSecretLoader) NotifyDefault(raw Raw) {
+	l.defaults[raw.Key] = raw
+}
+
+func (l *mockSecretLoader) NotifySecret(key string) {
+	l.secretKeys[key] = true
+}
+
+func TestSecret(t *testing.T) {
+	type helloConfig struct {
+		User     string
+		Password Secret[string]
+		token    Secret[string]
+	}
+	randomSecret := func() string {
+		return gofakeit.Password(true, true, true, false, false, 16)
+	}
+
+	t.Run("reveal value", func(t *testing.T) {
+		want := randomSecret()
+		assert.Equal(t, want, NewSecret(want).Reveal())
+		assert.Equal(t, "", Secret[string]{}.Reveal())
+	})
+	t.Run("redact when printed", func(t *testing.T) {
+		password := randomSecret()
+		cfg := helloConfig{
+			User:     gofakeit.Username(),
+			Password: NewSecret(password),
+			token:    NewSecret(password),
+		}
+		for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x"} {
+			got := fmt.Sprintf(format, cfg)
+			assert.NotContains(t, got, password, format)
+			assert.NotContains(t, fmt.Sprintf(format, &cfg), password, format)
+			assert.Equal(t, Redacted, fmt.Sprintf(format, cfg.Password), format)
+		}
+		assert.Equal(t, Redacted, cfg.Password.String())
+	})
+	t.Run("redact when marshaled to JSON", func(t *testing.T) {
+		password := randomSecret()
+		data, err := json.Marshal(helloConfig{Password: NewSecret(password)})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.JSONEq(t, `{"User": "", "Password": "[REDACTED]"}`, string(data))
+	})
+	t.Run("Define", func(t *testing.T) {
+		newLoader := func(values map[string]interface{}) *mockSecretLoader {
+			loader := &mockSecretLoader{
+				mockLoader: mockLoader{
+					rawByPath:    map[string]Raw{},
+					errorsByPath: map[string]error{},
+				},
+				defaults:   map[string]Raw{},
+				secretKeys: map[string]bool{},
+			}
+			for key, v := range values {
+				loader.rawByPath[key] = Raw{Key: key, Val: v}
+			}
+			return loader
+		}
+		t.Run("convert underlying value", func(t *testing.T) {
+			password := randomSecret()
+			loader := newLoader(map[string]interface{}{"password": password, "port": "5432"})
+			gotPassword := Define[Secret[string]](loader, "password")
+			gotPort := Define[Secret[int]](loader, "port")
+			assert.Empty(t, loader.errorsByPath)
+			assert.Equal(t, password, gotPassword.Reveal())
+			assert.Equal(t, 5432, gotPort.Reveal())
+		})
+		t.Run("redact conversion errors", func(t *testing.T) {
+			password := randomSecret()
+			loader := newLoader(map[string]interface{}{"port": password})
+			Define[Secret[int]](loader, "port")
+			err := loader.errorsByPath["port"]
+			if !assert.Error(t, err) {
+				return
+			}
+			assert.NotContains(t, err.Error(), password)
+			assert.True(t, strings.HasPrefix(err.Error(),
+				"error converting path port: failed to convert [REDACTED]{string} to int: "), err.Error())
+		})
+		t.Run("validate underlying value", func(t *testing.T) {
+			password := randomSecret()
+			loader := newLoader(map[string]interface{}{"password": password})
+			Define[Secret[string]](loader, "password", Validate(func(v string) error {
+				return fmt.Errorf("too weak")
+			}))
+			assert.EqualError(t, loader.errorsByPath["password"], "invalid value [REDACTED] of password: too weak")
+		})
+		t.Run("validate revealed value not empty", func(t *testing.T) {
+			loader := newLoader(map[string]interface{}{"password": ""})
+			Define[Secret[string]](loader, "password", NotEmpty())
+			assert.EqualError(t, loader.errorsByPath["password"], "invalid value [REDACTED] of password: must not be empty")
+		})
+		t.Run("use default", func(t *testing.T) {
+			want := randomSecret()
+			loader := newLoader(map[string]interface{}{})
+			got1 := Define[Secret[string]](loader, "password1", Default(want))
+			got2 := Define[Secret[string]](loader, "password2", Default(NewSecret(want)))
+			assert.Empty(t, loader.errorsByPath)
+			assert.Equal(t, want, got1.Reveal())
+			assert.Equal(t, want, got2.Reveal())
+			assert.Equal(t, Redacted, loader.defaults["password1"].Val)
+		})
+		t.Run("define struct with secret fields", func(t *testing.T) {
+			type dbConfig struct {
+				Host     string         `json:"host"`
+				Password Secret[string] `json:"password"`
+			}
+			password := randomSecret()
+			loader := newLoader(map[string]interface{}{
+				"db": map[string]interface{}{"host": "localhost", "password": password},
+			})
+			got := Define[dbConfig](loader, "db")
+			assert.Empty(t, loader.errorsByPath)
+			assert.Equal(t, "localhost", got.Host)
+			assert.Equal(t, password, got.Password.Reveal())
+		})
+		t.Run("redact conversion errors of struct with secret fields", func(t *testing.T) {
+			type dbConfig struct {
+				Port     int            `json:"port"`
+				Password Secret[string] `json:"password"`
+			}
+			password := randomSecret()
+			for _, rawVal := range []interface{}{
+				map[string]interface{}{"port": "not a number", "password": password},
+				`{"port": "not a number", "password": "` + password + `"}`,
+			} {
+				loader := newLoader(map[string]interface{}{"db": rawVal})
+				Define[dbConfig](loader, "db")
+				err := loader.errorsByPath["db"]
+				if !assert.Error(t, err) {
+					return
+				}
+				assert.NotContains(t, err.Error(), password)
+			}
+		})
+		t.Run("notify secret keys", func(t *testing.T) {
+			type dbConfig struct {
+				Host     string          `json:"host"`
+				Password *Secret[string] `json:"password"`
+			}
+			loader := newLoader(map[string]interface{}{
+				"password": randomSecret(),
+				"db":       []interface{}{map[string]interface{}{"host": "localhost"}},
+				"host":     "localhost",
+			})
+			Define[Secret[string]](loader, "password")
+			Define[[]dbConfig](loader, "db")
+			Define[string](loader, "host")
+			assert.Empty(t, loader.errorsByPath)
+			assert.Equal(t, map[string]bool{"password": true, "db": true}, loader.secretKeys)
+		})
+		t.Run("bind tagged fields", func(t *testing.T) {
+			type dbConfig struct {
+				Password Secret[string] `config:"db/password"`
+			}
+			password := randomSecret()
+			loader := newLoader(map[string]interface{}{"db/password": password})
+			got := Bind[dbConfig](loader)
+			assert.Empty(t, loader.errorsByPath)
+			assert.Equal(t, password, got.Password.Reveal())
+		})
+	})
+}
@@ -0,0 +1,133 @@
+package val
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+)
+
+// ErrInvalidValue is notified if a value failed validation
+type ErrInvalidValue struct {
+	Key   string
+	Value interface{}
+	Err   error
+}
+
+func (e ErrInvalidValue) Error() string {
+	return fmt.Sprintf("invalid value %v of %s: %v", e.Value, e.Key, e.Err)
+}
+
+func (e ErrInvalidValue) Unwrap() error {
+	return e.Err
+}
+
+type validator func(target reflect.Value) error
+
+// validate runs validators with the target. Reported value is
+// used in errors, it differs from the target for secrets.
+func validate(l Provider, key string, target, reported reflect.Value, validators []validator) {
+	for _, v := range validators {
+		if err := v(target); err != nil {
+			l.NotifyError(key, ErrInvalidValue{
+				Key:   key,
+				Value: reported.Interface(),
+				Err:   err,
+			})
+		}
+	}
+}
+
+type ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 |
+		~string
+}
+
+// typedValidator returns a validator that works with values of type T
+func typedValidator[T any](validate func(v T) error) validator {
+	return func(target reflect.Value) error {
+		v, ok := target.Interface().(T)
+		if !ok {
+			return fmt.Errorf("validator for %T can not be used with %v", v, target.Type())
+		}
+		return validate(v)
+	}
+}
+
+func withValidator(v validator) DefineOption {
+	return func(o *defineOptions) {
+		o.validators = append(o.validators, v)
+	}
+}
+
+// Min validates that the value is greater than or equal to min.
+// Type of min should match the type of the defined value.
+func Min[T ordered](min T) DefineOption {
+	return withValidator(typedValidator(func(v T) error {
+		if v < min {
+			return fmt.Errorf("must be greater than or equal to %v", min)
+		}
+		return nil
+	}))
+}
+
+// Max validates that the value is less than or equal to max.
+// Type of max should match the type of the defined value.
+func Max[T ordered](max T) DefineOption {
+	return withValidator(typedValidator(func(v T) error {
+		if v > max {
+			return fmt.Errorf("must be less than or equal to %v", max)
+		}
+		return nil
+	}))
+}
+
+// OneOf validates that the value is one of given values
+func OneOf[T comparable](values ...T) DefineOption {
+	return withValidator(typedValidator(func(v T) error {
+		for _, allowed := range values {
+			if v == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %v", values)
+	}))
+}
+
+// Matches validates that the string value matches the pattern
+func Matches(pattern *regexp.Regexp) DefineOption {
+	return withValidator(func(target reflect.Value) error {
+		if target.Kind() != reflect.String {
+			return fmt.Errorf("pattern can not be matched with %v", target.Type())
+		}
+		if !pattern.MatchString(target.String()) {
+			return fmt.Errorf("must match %s", pattern)
+		}
+		return nil
+	})
+}
+
+// NotEmpty validates that the value is not a zero value.
+// Strings, slices and maps must not have zero length.
+func NotEmpty() DefineOption {
+	return withValidator(func(target reflect.Value) error {
+		switch target.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+			if target.Len() == 0 {
+				return errors.New("must not be empty")
+			}
+		default:
+			if target.IsZero() {
+				return errors.New("must not be empty")
+			}
+		}
+		return nil
+	})
+}
+
+// Validate validates the value using given func
+func Validate[T any](validate func(v T) error) DefineOption {
+	return withValidator(typedValidator(validate))
+}
@@ -0,0 +1,168 @@
+package val
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	type testCase struct {
+		name    string
+		rawVal  interface{}
+		define  func(l Provider, key string)
+		wantErr bool
+	}
+	tests := []testCase{
+		{
+			name:   "min/valid",
+			rawVal: gofakeit.Number(10, 100),
+			define: func(l Provider, key string) { Define[int](l, key, Min(10)) },
+		},
+		{
+			name:    "min/invalid",
+			rawVal:  gofakeit.Number(-100, 9),
+			define:  func(l Provider, key string) { Define[int](l, key, Min(10)) },
+			wantErr: true,
+		},
+		{
+			name:    "min/type mismatch",
+			rawVal:  gofakeit.Float64Range(10, 100),
+			define:  func(l Provider, key string) { Define[float64](l, key, Min(1)) },
+			wantErr: true,
+		},
+		{
+			name:   "max/valid",
+			rawVal: "10s",
+			define: func(l Provider, key string) { Define[time.Duration](l, key, Max(time.Minute)) },
+		},
+		{
+			name:    "max/invalid",
+			rawVal:  "10m",
+			define:  func(l Provider, key string) { Define[time.Duration](l, key, Max(time.Minute)) },
+			wantErr: true,
+		},
+		{
+			name:   "one of/valid",
+			rawVal: "debug",
+			define: func(l Provider, key string) { Define[string](l, key, OneOf("debug", "info")) },
+		},
+		{
+			name:    "one of/invalid",
+			rawVal:  "trace",
+			define:  func(l Provider, key string) { Define[string](l, key, OneOf("debug", "info")) },
+			wantErr: true,
+		},
+		{
+			name:   "matches/valid",
+			rawVal: "https://" + gofakeit.DomainName(),
+			define: func(l Provider, key string) { Define[string](l, key, Matches(regexp.MustCompile("^https://"))) },
+		},
+		{
+			name:    "matches/invalid",
+			rawVal:  "http://" + gofakeit.DomainName(),
+			define:  func(l Provider, key string) { Define[string](l, key, Matches(regexp.MustCompile("^https://"))) },
+			wantErr: true,
+		},
+		{
+			name:    "matches/not a string",
+			rawVal:  gofakeit.Number(1, 100),
+			define:  func(l Provider, key string) { Define[int](l, key, Matches(regexp.MustCompile(".*"))) },
+			wantErr: true,
+		},
+		{
+			name:   "not empty/valid",
+			rawVal: gofakeit.Word(),
+			define: func(l Provider, key string) { Define[string](l, key, NotEmpty()) },
+		},
+		{
+			name:    "not empty/empty string",
+			rawVal:  "",
+			define:  func(l Provider, key string) { Define[string](l, key, NotEmpty()) },
+			wantErr: true,
+		},
+		{
+			name:    "not empty/empty slice",
+			rawVal:  []interface{}{},
+			define:  func(l Provider, key string) { Define[[]string](l, key, NotEmpty()) },
+			wantErr: true,
+		},
+		{
+			name:    "not empty/zero number",
+			rawVal:  0,
+			define:  func(l Provider, key string) { Define[int](l, key, NotEmpty()) },
+			wantErr: true,
+		},
+		{
+			name:   "validate/valid",
+			rawVal: gofakeit.Number(2, 50) * 2,
+			define: func(l Provider, key string) {
+				Define[int](l, key, Validate(func(v int) error {
+					if v%2 != 0 {
+						return errors.New("must be even")
+					}
+					return nil
+				}))
+			},
+		},
+		{
+			name:   "validate/invalid",
+			rawVal: gofakeit.Number(2, 50)*2 + 1,
+			define: func(l Provider, key string) {
+				Define[int](l, key, Validate(func(v int) error {
+					if v%2 != 0 {
+						return errors.New("must be even")
+					}
+					return nil
+				}))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			valPath := fmt.Sprintf("/path1/%s", gofakeit.Word())
+			loader := &mockLoader{
+				rawByPath:    map[string]Raw{valPath: {Val: tt.rawVal}},
+				errorsByPath: map[string]error{},
+			}
+			tt.define(loader, valPath)
+			gotErr := loader.errorsByPath[valPath]
+			if !tt.wantErr {
+				assert.NoError(t, gotErr)
+				return
+			}
+			var invalidErr ErrInvalidValue
+			if !assert.ErrorAs(t, gotErr, &invalidErr) {
+				return
+			}
+			assert.Equal(t, valPath, invalidErr.Key)
+			assert.NotNil(t, invalidErr.Value)
+		})
+	}
+
+	t.Run("validate default value", func(t *testing.T) {
+		valPath := fmt.Sprintf("/path1/%s", gofakeit.Word())
+		loader := &mockLoader{
+			rawByPath:    map[string]Raw{},
+			errorsByPath: map[string]error{},
+		}
+		Define[int](loader, valPath, Default(0), Min(1))
+		assert.ErrorAs(t, loader.errorsByPath[valPath], &ErrInvalidValue{})
+	})
+	t.Run("skip validation of missing optional value", func(t *testing.T) {
+		valPath := fmt.Sprintf("/path1/%s", gofakeit.Word())
+		loader := &mockLoader{
+			rawByPath:    map[string]Raw{},
+			errorsByPath: map[string]error{},
+		}
+		Define[int](loader, valPath, Optional(), Min(1))
+		assert.NoError(t, loader.errorsByPath[valPath])
+	})
+}
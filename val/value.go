@@ -18,12 +18,77 @@ type ErrConvertFailed struct {
 }
 
 func (e ErrConvertFailed) Error() string {
+	switch reflect.ValueOf(e.source).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		// Objects may hold sensitive values, so only the type is printed
+		return fmt.Sprintf("failed to convert %T to %v: %s", e.source, e.targetTypeName, e.message)
+	}
 	return fmt.Sprintf("failed to convert %[1]v{%[1]T} to %v: %s", e.source, e.targetTypeName, e.message)
 }
 
-type Raw struct {
+// ErrMissingValue is notified if a required value was not found
+type ErrMissingValue struct {
 	Key string
-	Val interface{}
+
+	// Sources that were checked for the value, may be empty
+	// if Provider does not know about sources
+	Sources []string
+}
+
+func (e ErrMissingValue) Error() string {
+	if len(e.Sources) == 0 {
+		return fmt.Sprintf("value %s not found", e.Key)
+	}
+	return fmt.Sprintf("value %s not found (sources: %s)", e.Key, strings.Join(e.Sources, ", "))
+}
+
+// ErrConversion is notified if a value could not be converted to the target type
+type ErrConversion struct {
+	Key string
+
+	// Source is a name of the source the value came from, may be empty
+	Source string
+
+	// TargetType is a name of the type the value was converted to
+	TargetType string
+
+	Err error
+}
+
+func (e ErrConversion) Error() string {
+	if e.Source == "" {
+		return fmt.Sprintf("error converting path %s: %v", e.Key, e.Err)
+	}
+	return fmt.Sprintf("error converting path %s from %s: %v", e.Key, e.Source, e.Err)
+}
+
+func (e ErrConversion) Unwrap() error {
+	return e.Err
+}
+
+// Origin describes where the raw value came from.
+// Fields that are not relevant for a source are left empty.
+type Origin struct {
+	// Source is a human readable name of the source
+	Source string
+
+	// File is a path of the file the value was read from
+	File string
+
+	// EnvVar is a name of the environment variable the value was read from
+	EnvVar string
+
+	// Flag is a name of the command line flag the value was read from
+	Flag string
+
+	// Pointer is a JSON pointer (RFC 6901) of the value within the file
+	Pointer string
+}
+
+type Raw struct {
+	Key    string
+	Val    interface{}
+	Origin Origin
 }
 
 type Provider interface {
@@ -61,6 +126,13 @@ func jsonMarshalSetValue(val interface{}, target reflect.Value) error {
 
 type typeConverter map[string]func(source interface{}, target reflect.Value) error
 
+// Converters of these types are matched by the type rather than
+// by the name, so user types named e.g Time do not use them
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
 var supportedConverters = typeConverter{
 	"string": func(val interface{}, target reflect.Value) error {
 		targetType := target.Type()
@@ -217,7 +289,7 @@ var supportedConverters = typeConverter{
 		return nil
 	},
 	"json-marshaled": jsonMarshalSetValue,
-	"Duration": func(val interface{}, target reflect.Value) error {
+	durationType.String(): func(val interface{}, target reflect.Value) error {
 		var durationVal time.Duration
 		var err error
 		switch actualVal := val.(type) {
@@ -234,22 +306,48 @@ var supportedConverters = typeConverter{
 		target.Set(reflect.ValueOf(durationVal))
 		return nil
 	},
+	timeType.String(): func(val interface{}, target reflect.Value) error {
+		var timeVal time.Time
+		var err error
+		switch actualVal := val.(type) {
+		case time.Time:
+			timeVal = actualVal
+		case string:
+			timeVal, err = time.Parse(time.RFC3339Nano, actualVal)
+		default:
+			err = errors.New("unexpected Time type")
+		}
+		if err != nil {
+			return err
+		}
+		target.Set(reflect.ValueOf(timeVal))
+		return nil
+	},
 }
 
-func (c typeConverter) convert(source interface{}, target reflect.Value) error {
+// converterName returns a name of the converter of the target type
+func converterName(target reflect.Value) string {
 	kind := target.Kind()
-	targetTypeName := kind.String()
-
 	switch {
+	case target.Type() == timeType || target.Type() == durationType:
+		return target.Type().String()
 	case kind == reflect.Struct || kind == reflect.Map:
-		targetTypeName = "json-marshaled"
+		return "json-marshaled"
 	case kind == reflect.Slice:
-		typeElem := target.Type().Elem().Kind().String()
-		targetTypeName = "[]" + typeElem
-	case targetTypeName != target.Type().Name():
-		targetTypeName = target.Type().Name()
+		elemKind := target.Type().Elem().Kind()
+		if elemKind == reflect.Struct || elemKind == reflect.Map {
+			return "json-marshaled"
+		}
+		return "[]" + elemKind.String()
+	case kind.String() != target.Type().Name():
+		return target.Type().Name()
+	default:
+		return kind.String()
 	}
+}
 
+func (c typeConverter) convert(source interface{}, target reflect.Value) error {
+	targetTypeName := converterName(target)
 	convert, ok := c[targetTypeName]
 	if !ok {
 		return ErrConvertFailed{
@@ -270,7 +368,10 @@ func (c typeConverter) convert(source interface{}, target reflect.Value) error {
 }
 
 type defineOptions struct {
-	optional bool
+	optional     bool
+	hasDefault   bool
+	defaultValue interface{}
+	validators   []validator
 }
 
 type DefineOption func(*defineOptions)
@@ -281,24 +382,131 @@ func Optional() DefineOption {
 	}
 }
 
+// Default sets a value that is used if none of the sources has the key.
+// Type of the value should match the type of the defined value,
+// otherwise an error is notified for the key.
+func Default[T any](v T) DefineOption {
+	return func(o *defineOptions) {
+		o.hasDefault = true
+		o.defaultValue = v
+	}
+}
+
+// CodeDefaultSource is a source name of values set with Default
+const CodeDefaultSource = "code default"
+
+// DefaultNotifier can optionally be implemented by a Provider
+// to be notified when a default value is used for a key
+type DefaultNotifier interface {
+	NotifyDefault(raw Raw)
+}
+
+// SecretNotifier can optionally be implemented by a Provider to be
+// notified about keys defined as a Secret or as a value holding one,
+// so the provider can redact their values, e.g in explanations
+type SecretNotifier interface {
+	NotifySecret(key string)
+}
+
+func setDefault(l Provider, key string, target reflect.Value, defaultValue interface{}, sensitive bool) bool {
+	defaultVal := reflect.ValueOf(defaultValue)
+	targetType := target.Type()
+	switch {
+	case defaultVal.IsValid() && defaultVal.Type().AssignableTo(targetType):
+		target.Set(defaultVal)
+	case defaultVal.IsValid() && defaultVal.Kind() == targetType.Kind() && defaultVal.CanConvert(targetType):
+		// Named types, e.g default int value for a type Port int
+		target.Set(defaultVal.Convert(targetType))
+	default:
+		l.NotifyError(key, fmt.Errorf("default value of %s: %T is not assignable to %v", key, defaultValue, targetType))
+		return false
+	}
+	if notifier, ok := l.(DefaultNotifier); ok {
+		if sensitive {
+			defaultValue = Redacted
+		}
+		notifier.NotifyDefault(Raw{
+			Key:    key,
+			Val:    defaultValue,
+			Origin: Origin{Source: CodeDefaultSource},
+		})
+	}
+	return true
+}
+
+// redactConvertErr removes the source value from the conversion error
+func redactConvertErr(err error) error {
+	var convertErr ErrConvertFailed
+	if !errors.As(err, &convertErr) {
+		return err
+	}
+	// Underlying errors like strconv ones may quote the value
+	if s := fmt.Sprint(convertErr.source); s != "" {
+		convertErr.message = strings.ReplaceAll(convertErr.message, s, Redacted)
+	}
+	convertErr.source = Redacted
+	return convertErr
+}
+
+func convertRaw(l Provider, key string, raw Raw, value, target reflect.Value, sensitive bool) bool {
+	err := supportedConverters.convert(raw.Val, value)
+	if err == nil {
+		return true
+	}
+	if sensitive {
+		err = redactConvertErr(err)
+	}
+	l.NotifyError(key, ErrConversion{
+		Key:        key,
+		Source:     raw.Origin.Source,
+		TargetType: target.Type().String(),
+		Err:        err,
+	})
+	return false
+}
+
+// defaultOf returns the default value to set to the underlying value of a secret
+func defaultOf(opts defineOptions, isSecret bool) interface{} {
+	if revealer, ok := opts.defaultValue.(secretRevealer); ok && isSecret {
+		// Default is a Secret itself
+		return revealer.revealAny()
+	}
+	return opts.defaultValue
+}
+
+func define(l Provider, key string, target reflect.Value, opts defineOptions) {
+	// Secrets are converted and validated as the underlying value, errors of
+	// values that are or hold secrets are reported with the value redacted
+	value := target
+	secret, isSecret := secretValue(target)
+	if isSecret {
+		value = secret
+	}
+	sensitive := isSecret || containsSecret(target.Type(), map[reflect.Type]bool{})
+	if notifier, ok := l.(SecretNotifier); ok && sensitive {
+		notifier.NotifySecret(key)
+	}
+	raw, ok := l.Get(key)
+	var set bool
+	switch {
+	case ok:
+		set = convertRaw(l, key, raw, value, target, sensitive)
+	case opts.hasDefault:
+		set = setDefault(l, key, value, defaultOf(opts, isSecret), sensitive)
+	case !opts.optional:
+		l.NotifyError(key, ErrMissingValue{Key: key})
+	}
+	if set {
+		validate(l, key, value, target, opts.validators)
+	}
+}
+
 func Define[T any](l Provider, key string, setOpts ...DefineOption) T {
 	var value T
 	opts := defineOptions{}
 	for _, opt := range setOpts {
 		opt(&opts)
 	}
-	raw, ok := l.Get(key)
-	if !ok {
-		if !opts.optional {
-			l.NotifyError(key, fmt.Errorf("value %s not found", key))
-		}
-		return value
-	}
-
-	valuePtr := reflect.ValueOf(&value).Elem()
-	err := supportedConverters.convert(raw.Val, valuePtr)
-	if err != nil {
-		l.NotifyError(key, fmt.Errorf("error converting path %s: %w", key, err))
-	}
+	define(l, key, reflect.ValueOf(&value).Elem(), opts)
 	return value
 }
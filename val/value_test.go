@@ -78,6 +78,10 @@ type testStruct struct {
 
 type stringAlias string
 
+// Types named same as types that have dedicated converters
+type Time string
+type Duration string
+
 func TestValue(t *testing.T) {
 	t.Run("types", func(t *testing.T) {
 		testCases := []func() valueTestCase{
@@ -354,6 +358,52 @@ func TestValue(t *testing.T) {
 				rawVal := gofakeit.Number(100, 200)
 				return makeValueTestCaseErr[time.Duration]("duration/from number", rawVal)
 			},
+			func() valueTestCase {
+				rawVal := int64(gofakeit.Number(100, 200))
+				return makeValueTestCaseErr[time.Duration]("duration/from int64", rawVal)
+			},
+			func() valueTestCase {
+				rawVal := gofakeit.Date()
+				return makeValueTestCase[time.Time]("time", rawVal, rawVal)
+			},
+			func() valueTestCase {
+				wantVal := gofakeit.Date().UTC()
+				return makeValueTestCase[time.Time]("time/from string", wantVal.Format(time.RFC3339Nano), wantVal)
+			},
+			func() valueTestCase {
+				return makeValueTestCaseErr[time.Time]("time/from invalid string", gofakeit.Word())
+			},
+			func() valueTestCase {
+				return makeValueTestCaseErr[time.Time]("time/from number", gofakeit.Number(100, 200))
+			},
+			func() valueTestCase {
+				return makeValueTestCaseErr[Time]("type named Time", gofakeit.Date().Format(time.RFC3339Nano))
+			},
+			func() valueTestCase {
+				return makeValueTestCaseErr[Duration]("type named Duration", gofakeit.Word())
+			},
+			func() valueTestCase {
+				wantVal := []testStruct{
+					{Key1: gofakeit.Word(), Key2: gofakeit.Word()},
+					{Key1: gofakeit.Word(), Key3: gofakeit.Word()},
+				}
+				rawVal := []interface{}{
+					map[string]interface{}{"key1": wantVal[0].Key1, "key2": wantVal[0].Key2},
+					map[string]interface{}{"key1": wantVal[1].Key1, "key3": wantVal[1].Key3},
+				}
+				return makeValueTestCase[[]testStruct]("slice struct", rawVal, wantVal)
+			},
+			func() valueTestCase {
+				wantVal := []map[string]string{
+					{"key1": gofakeit.Word()},
+					{"key2": gofakeit.Word()},
+				}
+				rawVal := []map[string]interface{}{
+					{"key1": wantVal[0]["key1"]},
+					{"key2": wantVal[1]["key2"]},
+				}
+				return makeValueTestCase[[]map[string]string]("slice map", rawVal, wantVal)
+			},
 		}
 
 		for _, tt := range testCases {
@@ -401,7 +451,7 @@ func TestValue(t *testing.T) {
 			gotVal1Val := Define[string](loader, val1Path)
 			assert.Equal(t, "", gotVal1Val)
 			assert.Len(t, loader.errorsByPath, 1)
-			assert.Equal(t, fmt.Errorf("value %s not found", val1Path), loader.errorsByPath[val1Path])
+			assert.Equal(t, ErrMissingValue{Key: val1Path}, loader.errorsByPath[val1Path])
 		})
 		t.Run("non existing optional", func(t *testing.T) {
 			val1Path := fmt.Sprintf("/path1/%s", gofakeit.Word())
@@ -409,6 +459,43 @@ func TestValue(t *testing.T) {
 			assert.Equal(t, "", gotVal1Val)
 			assert.Nil(t, loader.errorsByPath[val1Path])
 		})
+		newLoader := func() *mockLoader {
+			return &mockLoader{
+				rawByPath:    map[string]Raw{},
+				errorsByPath: map[string]error{},
+			}
+		}
+		t.Run("non existing with default", func(t *testing.T) {
+			loader := newLoader()
+			val1Path := fmt.Sprintf("/path1/%s", gofakeit.Word())
+			wantVal := time.Duration(gofakeit.Number(10, 100)) * time.Second
+			gotVal := Define[time.Duration](loader, val1Path, Default(wantVal))
+			assert.Equal(t, wantVal, gotVal)
+			assert.Empty(t, loader.errorsByPath)
+		})
+		t.Run("non existing with default of named type", func(t *testing.T) {
+			loader := newLoader()
+			val1Path := fmt.Sprintf("/path1/%s", gofakeit.Word())
+			wantVal := gofakeit.Word()
+			gotVal := Define[stringAlias](loader, val1Path, Default(wantVal))
+			assert.Equal(t, stringAlias(wantVal), gotVal)
+			assert.Empty(t, loader.errorsByPath)
+		})
+		t.Run("non existing with default of wrong type", func(t *testing.T) {
+			loader := newLoader()
+			val1Path := fmt.Sprintf("/path1/%s", gofakeit.Word())
+			gotVal := Define[int64](loader, val1Path, Default(gofakeit.Word()))
+			assert.Equal(t, int64(0), gotVal)
+			assert.Error(t, loader.errorsByPath[val1Path])
+		})
+		t.Run("existing with default", func(t *testing.T) {
+			loader := newLoader()
+			val1Path := fmt.Sprintf("/path1/%s", gofakeit.Word())
+			wantVal := gofakeit.SentenceSimple()
+			loader.rawByPath[val1Path] = Raw{Val: wantVal}
+			gotVal := Define[string](loader, val1Path, Default(gofakeit.Word()))
+			assert.Equal(t, wantVal, gotVal)
+		})
 		t.Run("invalid value", func(t *testing.T) {
 			val1Path := fmt.Sprintf("/path1/%s", gofakeit.Word())
 			rawByPath[val1Path] = Raw{Val: gofakeit.Date()}
@@ -417,5 +504,32 @@ func TestValue(t *testing.T) {
 			wantErr := ErrConvertFailed{}
 			assert.ErrorAs(t, loader.errorsByPath[val1Path], &wantErr)
 		})
+		t.Run("invalid object value error details", func(t *testing.T) {
+			loader := newLoader()
+			val1Path := fmt.Sprintf("/path1/%s", gofakeit.Word())
+			wantVal := gofakeit.Word()
+			loader.rawByPath[val1Path] = Raw{Val: map[string]interface{}{"key1": wantVal}}
+			Define[int](loader, val1Path)
+			err := loader.errorsByPath[val1Path]
+			if !assert.Error(t, err) {
+				return
+			}
+			assert.Equal(t, fmt.Sprintf("error converting path %s: failed to convert map[string]interface {} to int: unexpected int type", val1Path), err.Error())
+		})
+		t.Run("invalid value error details", func(t *testing.T) {
+			loader := newLoader()
+			val1Path := fmt.Sprintf("/path1/%s", gofakeit.Word())
+			wantSource := gofakeit.Generate("{word}.json")
+			loader.rawByPath[val1Path] = Raw{Val: gofakeit.Word(), Origin: Origin{Source: wantSource}}
+			Define[time.Duration](loader, val1Path)
+			var gotErr ErrConversion
+			if !assert.ErrorAs(t, loader.errorsByPath[val1Path], &gotErr) {
+				return
+			}
+			assert.Equal(t, val1Path, gotErr.Key)
+			assert.Equal(t, wantSource, gotErr.Source)
+			assert.Equal(t, "time.Duration", gotErr.TargetType)
+			assert.Contains(t, gotErr.Error(), fmt.Sprintf("error converting path %s from %s: ", val1Path, wantSource))
+		})
 	})
 }
@@ -0,0 +1,180 @@
+package vaultsrc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var errNotFound = errors.New("not found")
+
+// client is a minimal client of Vault HTTP API
+type client struct {
+	opts *loadOpts
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+func newClient(opts *loadOpts) *client {
+	return &client{
+		opts:  opts,
+		token: opts.token,
+	}
+}
+
+type apiResponse struct {
+	LeaseDuration int             `json:"lease_duration"`
+	Data          json.RawMessage `json:"data"`
+	Auth          *struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+	} `json:"auth"`
+	Errors []string `json:"errors"`
+}
+
+func (c *client) do(ctx context.Context, method, path, token string, body interface{}) (*apiResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.timeout)
+	defer cancel()
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	reqURL := strings.TrimSuffix(c.opts.address, "/") + "/v1/" + path
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if c.opts.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.opts.namespace)
+	}
+	res, err := c.opts.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	return readResponse(res)
+}
+
+func readResponse(res *http.Response) (*apiResponse, error) {
+	if res.StatusCode == http.StatusNotFound {
+		return nil, errNotFound
+	}
+	apiRes := &apiResponse{}
+	if err := json.NewDecoder(res.Body).Decode(apiRes); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		if len(apiRes.Errors) > 0 {
+			return nil, fmt.Errorf("unexpected status: %s: %s", res.Status, strings.Join(apiRes.Errors, ", "))
+		}
+		return nil, fmt.Errorf("unexpected status: %s", res.Status)
+	}
+	return apiRes, nil
+}
+
+// authToken returns a token to send with requests,
+// logging in with AppRole if token is missing or expired
+func (c *client) authToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.opts.appRole == nil {
+		return c.token, nil
+	}
+	if c.token != "" && (c.tokenExpiry.IsZero() || time.Now().Before(c.tokenExpiry)) {
+		return c.token, nil
+	}
+	res, err := c.do(ctx, http.MethodPost, "auth/"+c.opts.appRole.mount+"/login", "", map[string]string{
+		"role_id":   c.opts.appRole.roleID,
+		"secret_id": c.opts.appRole.secretID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to login with AppRole: %w", err)
+	}
+	if res.Auth == nil || res.Auth.ClientToken == "" {
+		return "", errors.New("failed to login with AppRole: no token in response")
+	}
+	c.token = res.Auth.ClientToken
+	c.tokenExpiry = time.Time{}
+	if res.Auth.LeaseDuration > 0 {
+		// Login again a bit before the token expires
+		lease := time.Duration(res.Auth.LeaseDuration) * time.Second
+		c.tokenExpiry = time.Now().Add(lease - lease/10)
+	}
+	return c.token, nil
+}
+
+func (c *client) get(ctx context.Context, path string) (*apiResponse, error) {
+	token, err := c.authToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(ctx, http.MethodGet, path, token, nil)
+}
+
+type secretVersion struct {
+	version int
+
+	// expiresAt is set if secret was read with a lease
+	expiresAt time.Time
+}
+
+type secret struct {
+	data map[string]interface{}
+	secretVersion
+}
+
+// readSecret reads the latest version of KV v2 secret
+func (c *client) readSecret(ctx context.Context, path string) (secret, error) {
+	res, err := c.get(ctx, c.opts.mount+"/data/"+path)
+	if err != nil {
+		return secret{}, err
+	}
+	var data struct {
+		Data     map[string]interface{} `json:"data"`
+		Metadata struct {
+			Version int `json:"version"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(res.Data, &data); err != nil {
+		return secret{}, fmt.Errorf("failed to decode secret: %w", err)
+	}
+	s := secret{
+		data:          data.Data,
+		secretVersion: secretVersion{version: data.Metadata.Version},
+	}
+	if res.LeaseDuration > 0 {
+		s.expiresAt = time.Now().Add(time.Duration(res.LeaseDuration) * time.Second)
+	}
+	return s, nil
+}
+
+// currentVersion reads KV v2 metadata of the secret
+func (c *client) currentVersion(ctx context.Context, path string) (int, error) {
+	res, err := c.get(ctx, c.opts.mount+"/metadata/"+path)
+	if err != nil {
+		return 0, err
+	}
+	var metadata struct {
+		CurrentVersion int `json:"current_version"`
+	}
+	if err := json.Unmarshal(res.Data, &metadata); err != nil {
+		return 0, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+	return metadata.CurrentVersion, nil
+}
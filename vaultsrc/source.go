@@ -0,0 +1,318 @@
+package vaultsrc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gocombo/config"
+	"github.com/gocombo/config/val"
+)
+
+// DefaultAddress is an address of the local Vault server
+const DefaultAddress = "http://127.0.0.1:8200"
+
+// DefaultPollInterval is used to check secrets for new versions
+// if poll interval is not configured
+const DefaultPollInterval = time.Minute
+
+// DefaultTimeout is a timeout of a single request
+const DefaultTimeout = 10 * time.Second
+
+type appRole struct {
+	mount    string
+	roleID   string
+	secretID string
+}
+
+type secretField struct {
+	path  string
+	field string
+}
+
+type loadOpts struct {
+	name         string
+	address      string
+	namespace    string
+	mount        string
+	token        string
+	appRole      *appRole
+	client       *http.Client
+	timeout      time.Duration
+	pollInterval time.Duration
+	keyToField   map[string]secretField
+}
+
+func defaultLoadOpts() loadOpts {
+	return loadOpts{
+		name:         "vault",
+		address:      DefaultAddress,
+		mount:        "secret",
+		client:       http.DefaultClient,
+		timeout:      DefaultTimeout,
+		pollInterval: DefaultPollInterval,
+		keyToField:   map[string]secretField{},
+	}
+}
+
+func (o *loadOpts) set(optSetter []LoadOpt) {
+	for _, opt := range optSetter {
+		opt(o)
+	}
+}
+
+type LoadOpt func(opts *loadOpts)
+
+// WithName sets a name of the source that is used in errors
+// and value origin. Default name is "vault".
+func WithName(name string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.name = name
+	}
+}
+
+// WithAddress sets an address of Vault server. Default is DefaultAddress.
+func WithAddress(address string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.address = address
+	}
+}
+
+// WithNamespace sets Vault Enterprise namespace
+func WithNamespace(namespace string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.namespace = namespace
+	}
+}
+
+// WithMount sets a mount path of KV v2 secrets engine. Default is "secret".
+func WithMount(mount string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.mount = strings.Trim(mount, "/")
+	}
+}
+
+// WithToken authenticates with the token
+func WithToken(token string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.token = token
+	}
+}
+
+// WithAppRole authenticates using AppRole auth method mounted at "approle".
+// Source logs in again when the token expires.
+func WithAppRole(roleID, secretID string) LoadOpt {
+	return WithAppRoleMount("approle", roleID, secretID)
+}
+
+// WithAppRoleMount is same as WithAppRole but uses AppRole auth method at given mount
+func WithAppRoleMount(mount, roleID, secretID string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.appRole = &appRole{
+			mount:    strings.Trim(mount, "/"),
+			roleID:   roleID,
+			secretID: secretID,
+		}
+	}
+}
+
+// WithClient sets a client that is used to send requests.
+// http.DefaultClient is used by default.
+func WithClient(client *http.Client) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.client = client
+	}
+}
+
+// WithTimeout sets a timeout of a single request. Default is DefaultTimeout,
+// non-positive values are ignored.
+func WithTimeout(timeout time.Duration) LoadOpt {
+	return func(opts *loadOpts) {
+		if timeout > 0 {
+			opts.timeout = timeout
+		}
+	}
+}
+
+// WithPollInterval defines how often secrets are checked for new versions
+// when config is watched. Default is DefaultPollInterval, non-positive
+// values are ignored.
+func WithPollInterval(interval time.Duration) LoadOpt {
+	return func(opts *loadOpts) {
+		if interval > 0 {
+			opts.pollInterval = interval
+		}
+	}
+}
+
+type LoadValOptBuilder struct {
+	valuePath string
+}
+
+// From defines a secret path (relative to the mount) and a field of the secret
+// to set value from, e.g From("myapp/db", "password")
+func (b *LoadValOptBuilder) From(secretPath, field string) LoadOpt {
+	return func(opts *loadOpts) {
+		opts.keyToField[b.valuePath] = secretField{
+			path:  strings.Trim(secretPath, "/"),
+			field: field,
+		}
+	}
+}
+
+// Set config value using From
+func Set(path string) *LoadValOptBuilder {
+	return &LoadValOptBuilder{
+		valuePath: path,
+	}
+}
+
+type source struct {
+	name        string
+	client      *client
+	versions    map[string]secretVersion
+	valuesByKey map[string]val.Raw
+}
+
+func (src *source) Name() string {
+	return src.name
+}
+
+func (src *source) GetValue(key string) (val.Raw, bool) {
+	rawVal, ok := src.valuesByKey[key]
+	if !ok {
+		return val.Raw{}, false
+	}
+	return rawVal, true
+}
+
+// changed checks if any of the secrets has changed since the last check
+func (src *source) changed(ctx context.Context) bool {
+	changed := false
+	for path, version := range src.versions {
+		if !version.expiresAt.IsZero() && !time.Now().Before(version.expiresAt) {
+			// Secret is read again to get a new lease
+			s, err := src.client.readSecret(ctx, path)
+			if err != nil {
+				// Failed requests are retried on the next poll
+				continue
+			}
+			src.versions[path] = s.secretVersion
+			changed = true
+			continue
+		}
+		currentVersion, err := src.client.currentVersion(ctx, path)
+		if err != nil {
+			continue
+		}
+		if currentVersion != version.version {
+			version.version = currentVersion
+			src.versions[path] = version
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Watch polls metadata of the secrets and notifies when a new
+// version is written or when a lease of the secret expires
+func (src *source) Watch(ctx context.Context, notify func()) {
+	ticker := time.NewTicker(src.client.opts.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if src.changed(ctx) {
+				notify()
+			}
+		}
+	}
+}
+
+// Load reads fields of KV v2 secrets mapped with Set. Secrets are read once per
+// path, missing fields are not present in the source. Token auth is used
+// with WithToken, AppRole auth with WithAppRole.
+func Load(optSetter ...LoadOpt) config.LoadOpt {
+	return func(opts config.LoadOpts) {
+		srcOpts := defaultLoadOpts()
+		srcOpts.set(optSetter)
+		opts.AddSourceLoader(config.NamedSourceLoader(srcOpts.name, func() (config.Source, error) {
+			return load(optSetter...)
+		}))
+	}
+}
+
+func load(optSetter ...LoadOpt) (config.Source, error) {
+	opts := defaultLoadOpts()
+	opts.set(optSetter)
+	src := &source{
+		name:        opts.name,
+		client:      newClient(&opts),
+		versions:    map[string]secretVersion{},
+		valuesByKey: map[string]val.Raw{},
+	}
+
+	// Sorted to read secrets in a stable order
+	keys := make([]string, 0, len(opts.keyToField))
+	for key := range opts.keyToField {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	secrets := map[string]secret{}
+	for _, key := range keys {
+		field := opts.keyToField[key]
+		s, ok := secrets[field.path]
+		if !ok {
+			var err error
+			s, err = src.client.readSecret(context.Background(), field.path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read secret %s: %w", field.path, err)
+			}
+			secrets[field.path] = s
+			src.versions[field.path] = s.secretVersion
+		}
+		v, ok := s.data[field.field]
+		if !ok || v == nil {
+			continue
+		}
+		src.valuesByKey[key] = val.Raw{
+			Key: key,
+			Val: v,
+			Origin: val.Origin{
+				Source: src.name,
+			},
+		}
+	}
+	return src, nil
+}
+
+// NewResolver returns a resolver of vault://path#field references in values
+// of other sources. Use it with config.WithResolver("vault", ...).
+// Secrets are read from KV v2 engine configured same way as for Load.
+func NewResolver(optSetter ...LoadOpt) config.Resolver {
+	opts := defaultLoadOpts()
+	opts.set(optSetter)
+	c := newClient(&opts)
+	return config.ResolverFunc(func(ref string) (interface{}, error) {
+		secretPath, field, ok := strings.Cut(strings.TrimPrefix(ref, "vault://"), "#")
+		if !ok || field == "" {
+			return nil, fmt.Errorf("expected vault://path#field, got %s", ref)
+		}
+		secretPath = strings.Trim(secretPath, "/")
+		s, err := c.readSecret(context.Background(), secretPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secret %s: %w", secretPath, err)
+		}
+		v, ok := s.data[field]
+		if !ok || v == nil {
+			return nil, fmt.Errorf("field %s not found in secret %s", field, secretPath)
+		}
+		return v, nil
+	})
+}
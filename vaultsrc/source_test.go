@@ -0,0 +1,486 @@
+package vaultsrc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/gocombo/config"
+	"github.com/gocombo/config/val"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockLoadOpts struct {
+	sourceLoaders []config.SourceLoader
+}
+
+func (m *mockLoadOpts) AddSourceLoader(loader config.SourceLoader) {
+	m.sourceLoaders = append(m.sourceLoaders, loader)
+}
+
+type mockSecret struct {
+	data    map[string]interface{}
+	version int
+}
+
+// mockVault is a minimal fake of Vault KV v2 and AppRole HTTP API
+type mockVault struct {
+	mu           sync.Mutex
+	token        string
+	mount        string
+	roleID       string
+	secretID     string
+	tokenLease   int
+	secretLease  int
+	secrets      map[string]mockSecret
+	logins       int
+	dataRequests int
+	gotNamespace string
+}
+
+func newMockVault() *mockVault {
+	return &mockVault{
+		token:   gofakeit.UUID(),
+		mount:   "secret",
+		secrets: map[string]mockSecret{},
+	}
+}
+
+func (m *mockVault) set(path string, data map[string]interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.secrets[path] = mockSecret{data: data, version: m.secrets[path].version + 1}
+}
+
+func (m *mockVault) stats() (logins int, dataRequests int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.logins, m.dataRequests
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func (m *mockVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gotNamespace = r.Header.Get("X-Vault-Namespace")
+	if r.URL.Path == "/v1/auth/approle/login" && r.Method == http.MethodPost {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["role_id"] != m.roleID || body["secret_id"] != m.secretID {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"errors": []string{"invalid role or secret ID"}})
+			return
+		}
+		m.logins++
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"auth": map[string]interface{}{
+				"client_token":   m.token,
+				"lease_duration": m.tokenLease,
+			},
+		})
+		return
+	}
+	if r.Header.Get("X-Vault-Token") != m.token {
+		writeJSON(w, http.StatusForbidden, map[string]interface{}{"errors": []string{"permission denied"}})
+		return
+	}
+	dataPrefix := "/v1/" + m.mount + "/data/"
+	metadataPrefix := "/v1/" + m.mount + "/metadata/"
+	switch {
+	case strings.HasPrefix(r.URL.Path, dataPrefix):
+		m.dataRequests++
+		s, ok := m.secrets[strings.TrimPrefix(r.URL.Path, dataPrefix)]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]interface{}{"errors": []string{}})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"lease_duration": m.secretLease,
+			"data": map[string]interface{}{
+				"data":     s.data,
+				"metadata": map[string]interface{}{"version": s.version},
+			},
+		})
+	case strings.HasPrefix(r.URL.Path, metadataPrefix):
+		s, ok := m.secrets[strings.TrimPrefix(r.URL.Path, metadataPrefix)]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]interface{}{"errors": []string{}})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"data": map[string]interface{}{"current_version": s.version},
+		})
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"errors": []string{}})
+	}
+}
+
+func TestVaultSource(t *testing.T) {
+	startVault := func(t *testing.T) (*mockVault, *httptest.Server) {
+		mock := newMockVault()
+		srv := httptest.NewServer(mock)
+		t.Cleanup(srv.Close)
+		return mock, srv
+	}
+
+	t.Run("load", func(t *testing.T) {
+		loadFromOpts := func(opts ...LoadOpt) (config.Source, error) {
+			mockOpts := &mockLoadOpts{}
+			loadOpt := Load(opts...)
+			loadOpt(mockOpts)
+			if len(mockOpts.sourceLoaders) < 1 {
+				return nil, fmt.Errorf("no source loader added to opts")
+			}
+			return mockOpts.sourceLoaders[0]()
+		}
+		t.Run("read mapped secret fields", func(t *testing.T) {
+			mock, srv := startVault(t)
+			password := gofakeit.Password(true, true, true, false, false, 16)
+			apiKey := gofakeit.UUID()
+			mock.set("myapp/db", map[string]interface{}{"user": gofakeit.Username(), "password": password})
+			mock.set("myapp/api", map[string]interface{}{"key": apiKey})
+			namespace := gofakeit.Word()
+			source, err := loadFromOpts(
+				WithAddress(srv.URL),
+				WithToken(mock.token),
+				WithNamespace(namespace),
+				Set("db/password").From("myapp/db", "password"),
+				Set("api/key").From("/myapp/api/", "key"),
+				Set("api/secret").From("myapp/api", "secret"),
+			)
+			if !assert.NoError(t, err) {
+				return
+			}
+			got, ok := source.GetValue("db/password")
+			if assert.True(t, ok, "Value db/password not found") {
+				assert.Equal(t, val.Raw{
+					Key:    "db/password",
+					Val:    password,
+					Origin: val.Origin{Source: "vault"},
+				}, got)
+			}
+			got, ok = source.GetValue("api/key")
+			if assert.True(t, ok, "Value api/key not found") {
+				assert.Equal(t, apiKey, got.Val)
+			}
+			_, ok = source.GetValue("api/secret")
+			assert.False(t, ok, "Value api/secret found")
+			_, dataRequests := mock.stats()
+			assert.Equal(t, 2, dataRequests)
+			assert.Equal(t, namespace, mock.gotNamespace)
+		})
+		t.Run("login with AppRole", func(t *testing.T) {
+			mock, srv := startVault(t)
+			mock.roleID = gofakeit.UUID()
+			mock.secretID = gofakeit.UUID()
+			password := gofakeit.Password(true, true, true, false, false, 16)
+			mock.set("myapp/db", map[string]interface{}{"password": password})
+			source, err := loadFromOpts(
+				WithAddress(srv.URL),
+				WithAppRole(mock.roleID, mock.secretID),
+				Set("db/password").From("myapp/db", "password"),
+			)
+			if !assert.NoError(t, err) {
+				return
+			}
+			got, ok := source.GetValue("db/password")
+			if assert.True(t, ok, "Value db/password not found") {
+				assert.Equal(t, password, got.Val)
+			}
+		})
+		t.Run("read secrets of given mount", func(t *testing.T) {
+			mock, srv := startVault(t)
+			mock.mount = gofakeit.Word()
+			password := gofakeit.Password(true, true, true, false, false, 16)
+			mock.set("myapp/db", map[string]interface{}{"password": password})
+			name := gofakeit.Word()
+			source, err := loadFromOpts(
+				WithAddress(srv.URL),
+				WithToken(mock.token),
+				WithMount("/"+mock.mount+"/"),
+				WithName(name),
+				Set("db/password").From("myapp/db", "password"),
+			)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, name, source.(config.Named).Name())
+			got, ok := source.GetValue("db/password")
+			if assert.True(t, ok, "Value db/password not found") {
+				assert.Equal(t, password, got.Val)
+			}
+		})
+		t.Run("fail on timeout", func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+			}))
+			defer srv.Close()
+			_, err := loadFromOpts(
+				WithAddress(srv.URL),
+				WithToken(gofakeit.UUID()),
+				WithTimeout(10*time.Millisecond),
+				Set("db/password").From("myapp/db", "password"),
+			)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		})
+		t.Run("use default timeout if not positive", func(t *testing.T) {
+			mock, srv := startVault(t)
+			mock.set("myapp/db", map[string]interface{}{"password": gofakeit.Word()})
+			for _, timeout := range []time.Duration{0, -time.Second} {
+				_, err := loadFromOpts(
+					WithAddress(srv.URL),
+					WithToken(mock.token),
+					WithTimeout(timeout),
+					Set("db/password").From("myapp/db", "password"),
+				)
+				assert.NoError(t, err)
+			}
+		})
+		t.Run("use given client", func(t *testing.T) {
+			mock, srv := startVault(t)
+			mock.set("myapp/db", map[string]interface{}{"password": gofakeit.Word()})
+			var used bool
+			client := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				used = true
+				return http.DefaultTransport.RoundTrip(r)
+			})}
+			_, err := loadFromOpts(
+				WithAddress(srv.URL),
+				WithToken(mock.token),
+				WithClient(client),
+				Set("db/password").From("myapp/db", "password"),
+			)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.True(t, used, "client not used")
+		})
+		t.Run("fail on invalid AppRole", func(t *testing.T) {
+			mock, srv := startVault(t)
+			mock.roleID = gofakeit.UUID()
+			mock.set("myapp/db", map[string]interface{}{"password": gofakeit.Word()})
+			_, err := loadFromOpts(
+				WithAddress(srv.URL),
+				WithAppRole(mock.roleID, gofakeit.UUID()),
+				Set("db/password").From("myapp/db", "password"),
+			)
+			assert.EqualError(t, err, "failed to load vault: failed to read secret myapp/db: "+
+				"failed to login with AppRole: unexpected status: 400 Bad Request: invalid role or secret ID")
+		})
+		t.Run("fail on invalid token", func(t *testing.T) {
+			mock, srv := startVault(t)
+			mock.set("myapp/db", map[string]interface{}{"password": gofakeit.Word()})
+			name := gofakeit.Word()
+			_, err := loadFromOpts(
+				WithAddress(srv.URL),
+				WithToken(gofakeit.UUID()),
+				WithName(name),
+				Set("db/password").From("myapp/db", "password"),
+			)
+			assert.EqualError(t, err, "failed to load "+name+": failed to read secret myapp/db: "+
+				"unexpected status: 403 Forbidden: permission denied")
+		})
+		t.Run("fail on missing secret", func(t *testing.T) {
+			mock, srv := startVault(t)
+			_, err := loadFromOpts(
+				WithAddress(srv.URL),
+				WithToken(mock.token),
+				Set("db/password").From("myapp/db", "password"),
+			)
+			assert.ErrorIs(t, err, errNotFound)
+		})
+		t.Run("define typed values", func(t *testing.T) {
+			mock, srv := startVault(t)
+			mock.set("myapp/db", map[string]interface{}{"port": 5432, "tls": true})
+			type dbConfig struct {
+				port int
+				tls  bool
+			}
+			cfg, err := config.Load(func(p val.Provider) *dbConfig {
+				return &dbConfig{
+					port: val.Define[int](p, "db/port"),
+					tls:  val.Define[bool](p, "db/tls"),
+				}
+			}, Load(
+				WithAddress(srv.URL),
+				WithToken(mock.token),
+				Set("db/port").From("myapp/db", "port"),
+				Set("db/tls").From("myapp/db", "tls"),
+			))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, &dbConfig{port: 5432, tls: true}, cfg)
+		})
+	})
+
+	t.Run("Watch", func(t *testing.T) {
+		watch := func(t *testing.T, source config.Source) <-chan struct{} {
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			notified := make(chan struct{}, 1)
+			go source.(config.Watchable).Watch(ctx, func() {
+				select {
+				case notified <- struct{}{}:
+				default:
+				}
+			})
+			return notified
+		}
+		assertNotified := func(t *testing.T, notified <-chan struct{}) {
+			select {
+			case <-notified:
+			case <-time.After(time.Second):
+				assert.Fail(t, "change not notified")
+			}
+		}
+		t.Run("notify on new version", func(t *testing.T) {
+			mock, srv := startVault(t)
+			mock.set("myapp/db", map[string]interface{}{"password": gofakeit.Word()})
+			source, err := load(
+				WithAddress(srv.URL),
+				WithToken(mock.token),
+				WithPollInterval(time.Millisecond),
+				Set("db/password").From("myapp/db", "password"),
+			)
+			if !assert.NoError(t, err) {
+				return
+			}
+			notified := watch(t, source)
+			time.Sleep(20 * time.Millisecond)
+			select {
+			case <-notified:
+				assert.Fail(t, "notified without changes")
+				return
+			default:
+			}
+			mock.set("myapp/db", map[string]interface{}{"password": gofakeit.Word()})
+			assertNotified(t, notified)
+		})
+		t.Run("use default interval if not positive", func(t *testing.T) {
+			mock, srv := startVault(t)
+			mock.set("myapp/db", map[string]interface{}{"password": gofakeit.Word()})
+			source, err := load(
+				WithAddress(srv.URL),
+				WithToken(mock.token),
+				WithPollInterval(-time.Second),
+				Set("db/password").From("myapp/db", "password"),
+			)
+			if !assert.NoError(t, err) {
+				return
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			assert.NotPanics(t, func() {
+				source.(config.Watchable).Watch(ctx, func() {})
+			})
+		})
+		t.Run("notify when lease expires", func(t *testing.T) {
+			mock, srv := startVault(t)
+			mock.secretLease = 1
+			mock.set("myapp/db", map[string]interface{}{"password": gofakeit.Word()})
+			source, err := load(
+				WithAddress(srv.URL),
+				WithToken(mock.token),
+				WithPollInterval(10*time.Millisecond),
+				Set("db/password").From("myapp/db", "password"),
+			)
+			if !assert.NoError(t, err) {
+				return
+			}
+			notified := watch(t, source)
+			select {
+			case <-notified:
+			case <-time.After(2 * time.Second):
+				assert.Fail(t, "lease expiration not notified")
+			}
+			_, dataRequests := mock.stats()
+			assert.Equal(t, 2, dataRequests)
+		})
+		t.Run("login again when token expires", func(t *testing.T) {
+			mock, srv := startVault(t)
+			mock.roleID = gofakeit.UUID()
+			mock.secretID = gofakeit.UUID()
+			mock.tokenLease = 1
+			mock.set("myapp/db", map[string]interface{}{"password": gofakeit.Word()})
+			source, err := load(
+				WithAddress(srv.URL),
+				WithAppRole(mock.roleID, mock.secretID),
+				WithPollInterval(10*time.Millisecond),
+				Set("db/password").From("myapp/db", "password"),
+			)
+			if !assert.NoError(t, err) {
+				return
+			}
+			watch(t, source)
+			assert.Eventually(t, func() bool {
+				logins, _ := mock.stats()
+				return logins > 1
+			}, 2*time.Second, 10*time.Millisecond)
+		})
+	})
+	t.Run("NewResolver", func(t *testing.T) {
+		type dbConfig struct {
+			password string
+		}
+		factory := func(p val.Provider) *dbConfig {
+			return &dbConfig{password: val.Define[string](p, "db/password")}
+		}
+		withRef := func(ref string) config.LoadOpt {
+			return func(opts config.LoadOpts) {
+				opts.AddSourceLoader(func() (config.Source, error) {
+					return &source{valuesByKey: map[string]val.Raw{
+						"db/password": {Key: "db/password", Val: ref},
+					}}, nil
+				})
+			}
+		}
+		t.Run("resolve references", func(t *testing.T) {
+			mock, srv := startVault(t)
+			password := gofakeit.Password(true, true, true, false, false, 16)
+			mock.set("db/creds", map[string]interface{}{"password": password})
+			cfg, err := config.Load(factory,
+				withRef("vault://db/creds#password"),
+				config.WithResolver("vault", NewResolver(WithAddress(srv.URL), WithToken(mock.token))),
+			)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, &dbConfig{password: password}, cfg)
+		})
+		t.Run("fail on missing field", func(t *testing.T) {
+			mock, srv := startVault(t)
+			mock.set("db/creds", map[string]interface{}{"user": gofakeit.Username()})
+			_, err := config.Load(factory,
+				withRef("vault://db/creds#password"),
+				config.WithResolver("vault", NewResolver(WithAddress(srv.URL), WithToken(mock.token))),
+			)
+			assert.EqualError(t, err, "failed building config: failed to resolve db/password "+
+				"from vault://db/creds#password: field password not found in secret db/creds")
+		})
+		t.Run("fail on invalid reference", func(t *testing.T) {
+			_, err := config.Load(factory,
+				withRef("vault://db/creds"),
+				config.WithResolver("vault", NewResolver()),
+			)
+			assert.ErrorContains(t, err, "expected vault://path#field, got vault://db/creds")
+		})
+	})
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
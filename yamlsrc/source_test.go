@@ -0,0 +1,240 @@
+package yamlsrc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/gocombo/config"
+	"github.com/gocombo/config/val"
+	"github.com/stretchr/testify/assert"
+)
+
+type closableBuffer bytes.Buffer
+
+func (b *closableBuffer) Read(p []byte) (n int, err error) {
+	return (*bytes.Buffer)(b).Read(p)
+}
+
+func (b *closableBuffer) Close() error {
+	return nil
+}
+
+type mockLoadOpts struct {
+	sourceLoaders []config.SourceLoader
+}
+
+func (m *mockLoadOpts) AddSourceLoader(loader config.SourceLoader) {
+	m.sourceLoaders = append(m.sourceLoaders, loader)
+}
+
+func withMockData(data string) LoadOpt {
+	return func(s *loadOpts) {
+		s.openFile = func(fileName string) (file io.ReadCloser, err error) {
+			return (*closableBuffer)(bytes.NewBufferString(data)), nil
+		}
+	}
+}
+
+func TestYamlSource(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		loadFromOpts := func(fileName string, opts ...LoadOpt) (config.Source, error) {
+			mockOpts := &mockLoadOpts{}
+			loadOpt := Load(fileName, opts...)
+			loadOpt(mockOpts)
+			if len(mockOpts.sourceLoaders) < 1 {
+				return nil, fmt.Errorf("no source loader added to opts")
+			}
+			return mockOpts.sourceLoaders[0]()
+		}
+		t.Run("fail if no such file", func(t *testing.T) {
+			fileName := gofakeit.Generate("{name}.yaml")
+			_, err := loadFromOpts(fileName)
+			assert.ErrorIs(t, err, os.ErrNotExist)
+			assert.Contains(t, err.Error(), fmt.Sprintf("failed to load %s: ", fileName))
+		})
+		t.Run("name source by file path", func(t *testing.T) {
+			fileName := gofakeit.Generate("{name}.yaml")
+			source, err := loadFromOpts(fileName, withMockData(""))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, fileName, source.(config.Named).Name())
+		})
+		t.Run("attribute errors to named source", func(t *testing.T) {
+			name := gofakeit.Word()
+			_, err := loadFromOpts(gofakeit.Generate("{name}.yaml"), WithName(name))
+			assert.ErrorIs(t, err, os.ErrNotExist)
+			assert.Contains(t, err.Error(), fmt.Sprintf("failed to load %s: ", name))
+		})
+		t.Run("optionally not fail if no such file", func(t *testing.T) {
+			source, err := loadFromOpts(gofakeit.Generate("{name}.yaml"), IgnoreMissingFile())
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.NotNil(t, source)
+		})
+		t.Run("fail if not a YAML", func(t *testing.T) {
+			_, err := loadFromOpts(gofakeit.Generate("{name}.yaml"), withMockData("key: [not closed"))
+			assert.ErrorContains(t, err, "failed to decode yaml")
+		})
+		t.Run("fail if not a mapping", func(t *testing.T) {
+			_, err := loadFromOpts(gofakeit.Generate("{name}.yaml"), withMockData("- item1\n- item2"))
+			assert.ErrorContains(t, err, "expected a mapping")
+		})
+		t.Run("load empty file", func(t *testing.T) {
+			source, err := loadFromOpts(gofakeit.Generate("{name}.yaml"), withMockData(""))
+			if !assert.NoError(t, err) {
+				return
+			}
+			_, ok := source.GetValue("key")
+			assert.False(t, ok)
+		})
+		t.Run("load from base dir", func(t *testing.T) {
+			wantFileName := gofakeit.Generate("{name}.yaml")
+			wantDir := gofakeit.Generate("/{name}/{name}")
+			var gotFilePath string
+			_, err := loadFromOpts(
+				wantFileName,
+				WithBaseDir(wantDir),
+				func(opts *loadOpts) {
+					opts.openFile = func(fileName string) (file io.ReadCloser, err error) {
+						gotFilePath = fileName
+						return (*closableBuffer)(bytes.NewBufferString("{}")), nil
+					}
+				},
+			)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, path.Join(wantDir, wantFileName), gotFilePath)
+		})
+	})
+
+	t.Run("GetValue", func(t *testing.T) {
+		strVal := gofakeit.Word()
+		nestedVal := gofakeit.Word()
+		port := gofakeit.Number(1000, 9000)
+		data := fmt.Sprintf(`
+str_val: %[1]s
+nested:
+  str_val: %[2]s
+base: &base
+  host: localhost
+  port: %[3]d
+server:
+  <<: *base
+  host: example.com
+alias: *base
+tags: [a, b]
+1: numeric key
+big: 18446744073709551615
+`, strVal, nestedVal, port)
+		source, err := load(gofakeit.Generate("{name}.yaml"), withMockData(data))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assertVal := func(key string, wantVal interface{}) {
+			gotVal, ok := source.GetValue(key)
+			if !assert.True(t, ok, "Value %s not found", key) {
+				return
+			}
+			assert.Equal(t, wantVal, gotVal.Val)
+		}
+		assertVal("str_val", strVal)
+		assertVal("nested/str_val", nestedVal)
+		assertVal("server/host", "example.com")
+		assertVal("server/port", port)
+		assertVal("alias/host", "localhost")
+		assertVal("tags", []interface{}{"a", "b"})
+		assertVal("1", "numeric key")
+		assertVal("big", float64(18446744073709551615))
+
+		_, ok := source.GetValue("not/existing/key")
+		assert.False(t, ok, "Value not/existing/key found")
+
+		gotVal, _ := source.GetValue("server/host")
+		assert.Equal(t, "/server/host", gotVal.Origin.Pointer)
+	})
+
+	t.Run("define values", func(t *testing.T) {
+		type server struct {
+			Host string `json:"host"`
+			Port int    `json:"port"`
+		}
+		type testConfig struct {
+			port    int
+			server  server
+			tags    []string
+			timeout time.Duration
+		}
+		tmpDir := t.TempDir()
+		fileName := gofakeit.Generate("{name}.yaml")
+		data := "server:\n  host: example.com\n  port: 8080\ntags: [a, b]\ntimeout: 10s\n"
+		if err := os.WriteFile(filepath.Join(tmpDir, fileName), []byte(data), 0o644); !assert.NoError(t, err) {
+			return
+		}
+		got, err := config.Load(func(p val.Provider) *testConfig {
+			return &testConfig{
+				port:    val.Define[int](p, "server/port"),
+				server:  val.Define[server](p, "server"),
+				tags:    val.Define[[]string](p, "tags"),
+				timeout: val.Define[time.Duration](p, "timeout"),
+			}
+		}, Load(fileName, WithBaseDir(tmpDir)))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, &testConfig{
+			port:    8080,
+			server:  server{Host: "example.com", Port: 8080},
+			tags:    []string{"a", "b"},
+			timeout: 10 * time.Second,
+		}, got)
+	})
+
+	t.Run("Watch", func(t *testing.T) {
+		t.Run("notify when file changes", func(t *testing.T) {
+			tmpDir := t.TempDir()
+			fileName := gofakeit.Generate("{name}.yaml")
+			writeValue := func(value string) {
+				data := []byte("key: " + value)
+				if err := os.WriteFile(filepath.Join(tmpDir, fileName), data, 0o644); !assert.NoError(t, err) {
+					t.FailNow()
+				}
+			}
+			writeValue(gofakeit.Word())
+			source, err := load(fileName, WithBaseDir(tmpDir), WithPollInterval(time.Millisecond))
+			if !assert.NoError(t, err) {
+				return
+			}
+			watchable, ok := source.(config.Watchable)
+			if !assert.True(t, ok, "source is not watchable") {
+				return
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			notified := make(chan struct{}, 1)
+			go watchable.Watch(ctx, func() {
+				select {
+				case notified <- struct{}{}:
+				default:
+				}
+			})
+			time.Sleep(20 * time.Millisecond)
+			writeValue(gofakeit.Word() + "-changed")
+			select {
+			case <-notified:
+			case <-time.After(time.Second):
+				assert.Fail(t, "change not notified")
+			}
+		})
+	})
+}